@@ -1,24 +1,112 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"strings"
 
-	"github.com/mat-sik/encoder-decoder/internal/ciphers"
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/mat-sik/encoder-decoder/internal/analysis"
+	"github.com/mat-sik/encoder-decoder/internal/commands"
 	"github.com/mat-sik/encoder-decoder/internal/parser"
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
 )
 
 func main() {
-	args := os.Args[1:]
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	argMap, err := parser.Parse(args)
-	if err != nil {
-		panic(err)
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if err := commands.Run(args, stdout); err != nil {
+		exitCode, message := describeError(err)
+		_, _ = fmt.Fprintln(stderr, "encoder-decoder: "+message)
+		return exitCode
 	}
-	cipherRunner, err := ciphers.NewCipherRunner(argMap)
-	if err != nil {
-		panic(err)
+	return commands.ExitOK
+}
+
+func describeError(err error) (int, string) {
+	var (
+		errInvalidArg         *parser.ErrInvalidArg
+		errMissingFlag        *parser.ErrMissingFlag
+		errMissingValue       *parser.ErrMissingValue
+		errDuplicateFlag      *parser.ErrDuplicateFlag
+		errUnknownAlgorithm   *parser.ErrUnknownAlgorithm
+		errInvalidStage       *parser.ErrInvalidStage
+		errUnknownMode        *parser.ErrUnknownMode
+		errUnknownUnit        *parser.ErrUnknownUnit
+		errInvalidKey         *algorithms.ErrInvalidKey
+		errInvalidAlphabet    *algorithms.ErrInvalidAlphabet
+		errUnsupportedUnit    *cipher.ErrUnsupportedUnit
+		errMissingParam       *cipher.ErrMissingParam
+		errUnsupportedParam   *cipher.ErrUnsupportedParam
+		errInvalidParam       *cipher.ErrInvalidParam
+		errUnsupportedVersion *cipher.ErrUnsupportedVersion
+		errInvalidFlagValue   *parser.ErrInvalidFlagValue
+		errUnknownLanguage    *commands.ErrUnknownLanguage
+		errUncrackable        *commands.ErrUncrackableAlgorithm
+		errFrequencyTable     *analysis.ErrInvalidFrequencyTable
+		errUnknownCommand     *commands.ErrUnknownCommand
+		errUnknownFlag        *commands.ErrUnknownFlag
+		errInvalidUTF8        *transformer.ErrInvalidUTF8
+		errUntransformable    *transformer.ErrUntransformableRune
+		errPath               *fs.PathError
+	)
+	switch {
+	case errors.As(err, &errInvalidArg),
+		errors.As(err, &errUnknownFlag),
+		errors.As(err, &errMissingFlag),
+		errors.As(err, &errMissingValue),
+		errors.As(err, &errDuplicateFlag),
+		errors.As(err, &errInvalidStage),
+		errors.As(err, &errInvalidFlagValue),
+		errors.As(err, &errUnknownLanguage),
+		errors.As(err, &errUncrackable),
+		errors.As(err, &errFrequencyTable),
+		errors.Is(err, commands.ErrCrackOutput),
+		errors.As(err, &errMissingParam),
+		errors.As(err, &errUnsupportedParam),
+		errors.As(err, &errInvalidParam),
+		errors.As(err, &errInvalidKey),
+		errors.As(err, &errInvalidAlphabet):
+		return commands.ExitUsage, err.Error()
+	case errors.Is(err, commands.ErrMissingCommand), errors.As(err, &errUnknownCommand):
+		return commands.ExitUsage, fmt.Sprintf("%s, available commands: %s", err, strings.Join(commandNames(), ", "))
+	case errors.Is(err, commands.ErrVerificationFailed),
+		errors.Is(err, cipher.ErrWrongKeyOrCorrupted),
+		errors.Is(err, cipher.ErrAuthenticationFailed),
+		errors.Is(err, commands.ErrEmptySample),
+		errors.Is(err, analysis.ErrNotEnoughLetters),
+		errors.Is(err, cipher.ErrCorruptedHeader),
+		errors.As(err, &errUnsupportedVersion),
+		errors.As(err, &errUntransformable):
+		return commands.ExitInvalidInput, err.Error()
+	case errors.As(err, &errUnknownAlgorithm):
+		return commands.ExitUsage, fmt.Sprintf("%s, available algorithms: %s", err, strings.Join(parser.AlgNames(), ", "))
+	case errors.As(err, &errUnknownMode):
+		return commands.ExitUsage, fmt.Sprintf("%s, available modes: %s, %s", err, parser.Encode, parser.Decode)
+	case errors.As(err, &errUnknownUnit):
+		return commands.ExitUsage, fmt.Sprintf("%s, available units: %s, %s", err, parser.Rune, parser.Byte)
+	case errors.As(err, &errUnsupportedUnit):
+		return commands.ExitUsage, fmt.Sprintf("%s, choose the other unit with: %s or %s", err, parser.ChosenUnit, parser.ChosenUnitFull)
+	case errors.As(err, &errInvalidUTF8):
+		return commands.ExitInvalidInput, fmt.Sprintf("the input is not valid UTF-8 text, %s, to transform binary data use: %s=%s, "+
+			"to tolerate the invalid bytes use: %s=%s", err, parser.ChosenUnitFull, parser.Byte, parser.InvalidFull, transformer.InvalidReplace)
+	case errors.As(err, &errPath):
+		return commands.ExitIO, err.Error()
+	default:
+		return commands.ExitFailure, err.Error()
 	}
-	if err = cipherRunner.Run(); err != nil {
-		panic(err)
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands.Commands))
+	for _, command := range commands.Commands {
+		names = append(names, command.Name)
 	}
+	return names
 }
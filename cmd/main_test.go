@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"github.com/mat-sik/encoder-decoder/internal/commands"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_run_exitCodes(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	validPath := filepath.Join(dir, "valid.txt")
+	invalidPath := filepath.Join(dir, "invalid.txt")
+	polishPath := filepath.Join(dir, "polish.txt")
+	outPath := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(validPath, []byte("hello"), 0644); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(invalidPath, []byte{'a', 0xFF}, 0644); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(polishPath, []byte("zażółć"), 0644); err != nil {
+		panic(err)
+	}
+	inputs := [][]string{
+		{"-m=encode", "-a=caesar", "-k=3", "-i=" + validPath, "-o=" + outPath},
+		{"-m=encode", "-a=caesar", "-i=" + validPath, "-o=" + outPath},
+		{"-m=encode", "-a=rot", "-k=3", "-i=" + validPath, "-o=" + outPath},
+		{"-m=encode", "-a=caesar", "-k=three", "-i=" + validPath, "-o=" + outPath},
+		{"-m=encode", "-a=caesar", "-k=3", "-i=" + invalidPath, "-o=" + outPath},
+		{"-m=encode", "-a=caesar", "-k=3", "-i=" + filepath.Join(dir, "missing.txt"), "-o=" + outPath},
+		{"-m=encode", "-a=mirror", "--domain=latin1", "-i=" + polishPath, "-o=" + outPath},
+		{"-m=encode", "-a=mirror", "--domain=ascii", "-i=" + polishPath, "-o=" + outPath},
+		{"-m=encode", "-a=affine", "-k=4:1", "-i=" + validPath, "-o=" + outPath},
+	}
+	expectedCodes := []int{
+		commands.ExitOK, commands.ExitUsage, commands.ExitUsage, commands.ExitUsage, commands.ExitInvalidInput,
+		commands.ExitIO, commands.ExitInvalidInput, commands.ExitUsage, commands.ExitUsage,
+	}
+	for i, input := range inputs {
+		stderr := new(bytes.Buffer)
+		// when
+		resultCode := run(input, new(bytes.Buffer), stderr)
+		// then
+		assert.Equal(t, expectedCodes[i], resultCode, input)
+		assert.Equal(t, expectedCodes[i] != commands.ExitOK, stderr.Len() != 0, input)
+	}
+}
+
+func Test_run_help(t *testing.T) {
+	// given
+	inputs := [][]string{
+		{},
+		{"-h"},
+		{"--help", "--algorithm=caesar"},
+	}
+	expectedContents := []string{"Algorithms:", "Algorithms:", "Algorithm: caesar"}
+	for i, input := range inputs {
+		stdout := new(bytes.Buffer)
+		// when
+		resultCode := run(input, stdout, new(bytes.Buffer))
+		// then
+		assert.Equal(t, commands.ExitOK, resultCode, input)
+		assert.Contains(t, stdout.String(), expectedContents[i], input)
+	}
+}
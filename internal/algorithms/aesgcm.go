@@ -0,0 +1,174 @@
+package algorithms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// The encrypted stream starts with the header: the salt, the PBKDF2 iteration count and the chunk size,
+// which is authenticated along with every chunk. Every chunk of the plaintext is sealed separately and
+// framed with the final chunk flag and the length of the sealed chunk. The nonce of a chunk is made of its
+// counter and of the final chunk flag, so that the chunks can be neither reordered nor dropped.
+const (
+	aesGcmSaltSize      = 16
+	aesGcmKeySize       = 32
+	aesGcmHeaderSize    = aesGcmSaltSize + 8
+	aesGcmFrameSize     = 5
+	MaxAesGcmIterations = 10_000_000
+	MaxAesGcmChunkSize  = 16 * 1024 * 1024
+)
+
+// NewAesGcmEncryptFunc returns the function encrypting the whole reader into the writer with AES-256-GCM,
+// under the key derived from the passphrase with a fresh random salt. The plaintext is sealed in chunks
+// of the given size, limited to 1-MaxAesGcmChunkSize, so the memory use does not depend on the size of
+// the stream.
+func NewAesGcmEncryptFunc(passphrase string, iterations int) (func(io.Reader, io.Writer, int) error, error) {
+	if passphrase == "" {
+		return nil, &ErrInvalidKey{passphrase, "passphrase must not be empty"}
+	}
+	if iterations < 1 || iterations > MaxAesGcmIterations {
+		return nil, &ErrInvalidKey{strconv.Itoa(iterations), "iteration count must be within: 1-" + strconv.Itoa(MaxAesGcmIterations)}
+	}
+	return func(reader io.Reader, writer io.Writer, chunkSize int) error {
+		chunkSize = min(max(chunkSize, 1), MaxAesGcmChunkSize)
+		header := make([]byte, aesGcmSaltSize, aesGcmHeaderSize)
+		if _, err := rand.Read(header); err != nil {
+			return err
+		}
+		header = binary.BigEndian.AppendUint32(header, uint32(iterations))
+		header = binary.BigEndian.AppendUint32(header, uint32(chunkSize))
+		aead, err := newAesGcm(passphrase, header)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(header); err != nil {
+			return err
+		}
+		return sealChunks(reader, writer, aead, header, chunkSize)
+	}, nil
+}
+
+func sealChunks(reader io.Reader, writer io.Writer, aead cipher.AEAD, header []byte, chunkSize int) error {
+	chunk := make([]byte, chunkSize)
+	frame := make([]byte, 0, aesGcmFrameSize+chunkSize+aead.Overhead())
+	for counter := uint64(0); ; counter++ {
+		n, err := io.ReadFull(reader, chunk)
+		final := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !final {
+			return err
+		}
+		frame = append(frame[:0], boolByte(final))
+		frame = binary.BigEndian.AppendUint32(frame, uint32(n+aead.Overhead()))
+		frame = aead.Seal(frame, chunkNonce(aead, counter, final), chunk[:n], header)
+		if _, err = writer.Write(frame); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// NewAesGcmDecryptFunc returns the function decrypting the stream encrypted by the function returned by
+// NewAesGcmEncryptFunc. Only the authenticated chunks are written, so ErrAuthenticationFailed is returned
+// before any of the tampered data, or of the data decrypted with a wrong passphrase, reaches the writer.
+func NewAesGcmDecryptFunc(passphrase string) (func(io.Reader, io.Writer) error, error) {
+	if passphrase == "" {
+		return nil, &ErrInvalidKey{passphrase, "passphrase must not be empty"}
+	}
+	return func(reader io.Reader, writer io.Writer) error {
+		header := make([]byte, aesGcmHeaderSize)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return truncatedAsAuthenticationFailed(err)
+		}
+		iterations := binary.BigEndian.Uint32(header[aesGcmSaltSize:])
+		chunkSize := binary.BigEndian.Uint32(header[aesGcmSaltSize+4:])
+		if iterations < 1 || iterations > MaxAesGcmIterations || chunkSize > MaxAesGcmChunkSize {
+			return ErrAuthenticationFailed
+		}
+		aead, err := newAesGcm(passphrase, header)
+		if err != nil {
+			return err
+		}
+		return openChunks(reader, writer, aead, header, int(chunkSize))
+	}, nil
+}
+
+func openChunks(reader io.Reader, writer io.Writer, aead cipher.AEAD, header []byte, chunkSize int) error {
+	frame := make([]byte, aesGcmFrameSize)
+	sealed := make([]byte, chunkSize+aead.Overhead())
+	chunk := make([]byte, 0, chunkSize)
+	for counter := uint64(0); ; counter++ {
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return truncatedAsAuthenticationFailed(err)
+		}
+		final := frame[0] == boolByte(true)
+		sealedSize := int(binary.BigEndian.Uint32(frame[1:]))
+		if sealedSize > len(sealed) {
+			return ErrAuthenticationFailed
+		}
+		if _, err := io.ReadFull(reader, sealed[:sealedSize]); err != nil {
+			return truncatedAsAuthenticationFailed(err)
+		}
+		var err error
+		if chunk, err = aead.Open(chunk[:0], chunkNonce(aead, counter, final), sealed[:sealedSize], header); err != nil {
+			return ErrAuthenticationFailed
+		}
+		if _, err = writer.Write(chunk); err != nil {
+			return err
+		}
+		if final {
+			break
+		}
+	}
+	_, err := io.ReadFull(reader, frame[:1])
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return ErrAuthenticationFailed
+	}
+}
+
+// truncatedAsAuthenticationFailed reports the stream ending too early as tampered with, and passes the other
+// read errors through.
+func truncatedAsAuthenticationFailed(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrAuthenticationFailed
+	}
+	return err
+}
+
+func newAesGcm(passphrase string, header []byte) (cipher.AEAD, error) {
+	iterations := int(binary.BigEndian.Uint32(header[aesGcmSaltSize:]))
+	key := pbkdf2(sha256.New, []byte(passphrase), header[:aesGcmSaltSize], iterations, aesGcmKeySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(aead cipher.AEAD, counter uint64, final bool) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce, counter)
+	nonce[len(nonce)-1] = boolByte(final)
+	return nonce
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var ErrAuthenticationFailed = errors.New("wrong passphrase or tampered data")
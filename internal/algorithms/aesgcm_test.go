@@ -0,0 +1,117 @@
+package algorithms
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const testIterations = 16
+
+func encryptTest(t *testing.T, plaintext string, chunkSize int) []byte {
+	encryptFunc, err := NewAesGcmEncryptFunc("correct horse", testIterations)
+	assert.NoError(t, err)
+	encrypted := new(bytes.Buffer)
+	assert.NoError(t, encryptFunc(strings.NewReader(plaintext), encrypted, chunkSize))
+	return encrypted.Bytes()
+}
+
+func Test_NewAesGcmEncryptFunc_roundTrip(t *testing.T) {
+	// given
+	inputs := []string{"", "abc", "abcdefgh", "Zażółć gęślą jaźń ✈ and a bit more"}
+	decryptFunc, err := NewAesGcmDecryptFunc("correct horse")
+	assert.NoError(t, err)
+	for _, input := range inputs {
+		encrypted := encryptTest(t, input, 4)
+		decrypted := new(bytes.Buffer)
+		// when
+		resultErr := decryptFunc(bytes.NewReader(encrypted), decrypted)
+		// then
+		assert.NoError(t, resultErr, input)
+		assert.Equal(t, input, decrypted.String())
+	}
+}
+
+func Test_NewAesGcmEncryptFunc_freshSalt(t *testing.T) {
+	// when
+	first := encryptTest(t, "abc", 4)
+	second := encryptTest(t, "abc", 4)
+	// then
+	assert.NotEqual(t, first, second)
+}
+
+func Test_NewAesGcmDecryptFunc_authenticationFailed(t *testing.T) {
+	// given
+	encrypted := encryptTest(t, "abcdefghij", 4)
+	flipped := bytes.Clone(encrypted)
+	flipped[aesGcmHeaderSize+aesGcmFrameSize]++
+	flippedSalt := bytes.Clone(encrypted)
+	flippedSalt[0]++
+	chunkFrameSize := aesGcmFrameSize + 4 + 16
+	dropped := append(bytes.Clone(encrypted[:aesGcmHeaderSize]), encrypted[aesGcmHeaderSize+chunkFrameSize:]...)
+	inputs := map[string][]byte{
+		"flipped chunk": flipped,
+		"flipped salt":  flippedSalt,
+		"dropped chunk": dropped,
+		"truncated":     encrypted[:len(encrypted)-chunkFrameSize+2],
+		"without final": encrypted[:aesGcmHeaderSize+2*chunkFrameSize],
+		"trailing data": append(bytes.Clone(encrypted), 0),
+		"header only":   encrypted[:aesGcmHeaderSize-1],
+	}
+	decryptFunc, _ := NewAesGcmDecryptFunc("correct horse")
+	wrongDecryptFunc, _ := NewAesGcmDecryptFunc("wrong horse")
+	// when
+	wrongErr := wrongDecryptFunc(bytes.NewReader(encrypted), io.Discard)
+	// then
+	assert.Equal(t, ErrAuthenticationFailed, wrongErr)
+	for name, input := range inputs {
+		// when
+		resultErr := decryptFunc(bytes.NewReader(input), io.Discard)
+		// then
+		assert.Equal(t, ErrAuthenticationFailed, resultErr, name)
+	}
+}
+
+func Test_NewAesGcmDecryptFunc_readError(t *testing.T) {
+	// given
+	encrypted := encryptTest(t, "abcdefghij", 4)
+	readErr := errors.New("read failed")
+	decryptFunc, _ := NewAesGcmDecryptFunc("correct horse")
+	for _, size := range []int{0, aesGcmHeaderSize, aesGcmHeaderSize + 2, len(encrypted)} {
+		reader := io.MultiReader(bytes.NewReader(encrypted[:size]), iotest.ErrReader(readErr))
+		// when
+		resultErr := decryptFunc(reader, io.Discard)
+		// then
+		assert.Equal(t, readErr, resultErr, size)
+	}
+}
+
+func Test_NewAesGcmEncryptFunc_chunkSize(t *testing.T) {
+	// given
+	chunkSizes := []int{-1, 0, 1, 4}
+	expectedSizes := []int{1, 1, 1, 4}
+	for i, chunkSize := range chunkSizes {
+		// when
+		encrypted := encryptTest(t, "abcdefgh", chunkSize)
+		// then
+		frames := (8 + expectedSizes[i]) / expectedSizes[i]
+		assert.Len(t, encrypted, aesGcmHeaderSize+frames*(aesGcmFrameSize+16)+8, chunkSize)
+	}
+}
+
+func Test_NewAesGcmEncryptFunc_invalidKey(t *testing.T) {
+	// given
+	passphrases := []string{"", "horse", "horse"}
+	iterations := []int{testIterations, 0, MaxAesGcmIterations + 1}
+	for i := range passphrases {
+		// when
+		resultFunc, resultErr := NewAesGcmEncryptFunc(passphrases[i], iterations[i])
+		// then
+		assert.Nil(t, resultFunc)
+		assert.IsType(t, &ErrInvalidKey{}, resultErr)
+	}
+}
@@ -0,0 +1,95 @@
+package algorithms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AffineKeySeparators separate the multiplier from the offset of the affine key, e.g. 5,8. Within a stage
+// of a pipeline, where the comma separates the stages, it is the colon, e.g. affine:5:8.
+const AffineKeySeparators = ",:"
+
+// AffineKey maps the rune of the index x within its alphabet onto the rune of the index a*x + b.
+type AffineKey struct {
+	A int
+	B int
+}
+
+func ParseAffineKey(key string) (AffineKey, error) {
+	aString, bString, ok := cutAny(key, AffineKeySeparators)
+	a, aErr := strconv.Atoi(strings.TrimSpace(aString))
+	b, bErr := strconv.Atoi(strings.TrimSpace(bString))
+	if !ok || aErr != nil || bErr != nil {
+		return AffineKey{}, &ErrInvalidKey{key, "key must be the multiplier and the offset separated with a comma or a colon, e.g. 5,8"}
+	}
+	return AffineKey{a, b}, nil
+}
+
+// String separates the multiplier from the offset with the colon, so that the key fits within a pipeline stage.
+func (key AffineKey) String() string {
+	return strconv.Itoa(key.A) + ":" + strconv.Itoa(key.B)
+}
+
+// cutAny works like strings.Cut, but cuts around the first of any of the single byte separators.
+func cutAny(s string, separators string) (before string, after string, found bool) {
+	if i := strings.IndexAny(s, separators); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}
+
+// affineMap is the a*x + b of a single alphabet, with both coefficients reduced modulo its size.
+type affineMap struct {
+	multiplier int
+	offset     int
+}
+
+// NewAffineRuneFunc returns the function mapping the runes within the first of the alphabets containing
+// them, leaving the other runes untouched. The multiplier has to be coprime with the size of every
+// alphabet, so that it has the modular inverse the decoding multiplies by. With the multiplier of 1 it
+// is the Caesar cipher.
+func NewAffineRuneFunc(key AffineKey, forward bool, alphabets ...*Alphabet) (func(rune) rune, error) {
+	if key.A == 1 && key.B == 0 {
+		return nil, &ErrInvalidKey{key.String(), "multiplier of 1 with zero offset does not do anything"}
+	}
+	affineMaps := make(map[*Alphabet]affineMap, len(alphabets))
+	for _, alphabet := range alphabets {
+		size := alphabet.Size()
+		inverse, ok := modInverse(key.A, size)
+		if !ok {
+			reason := fmt.Sprintf("multiplier: %d must be coprime with the alphabet size: %d", key.A, size)
+			return nil, &ErrInvalidKey{key.String(), reason}
+		}
+		if forward {
+			affineMaps[alphabet] = affineMap{mod(key.A, size), mod(key.B, size)}
+		} else {
+			// x = a⁻¹(y - b) = a⁻¹y - a⁻¹b
+			affineMaps[alphabet] = affineMap{inverse, mod(-inverse*mod(key.B, size), size)}
+		}
+	}
+	return func(r rune) rune {
+		alphabet, i, ok := findAlphabet(alphabets, r)
+		if !ok {
+			return r
+		}
+		affineMap := affineMaps[alphabet]
+		return alphabet.rune((affineMap.multiplier*i + affineMap.offset) % alphabet.Size())
+	}, nil
+}
+
+// modInverse finds the x such that a*x = 1 modulo m with the extended Euclidean algorithm. It exists
+// only if a and m are coprime.
+func modInverse(a int, m int) (int, bool) {
+	oldRemainder, remainder := mod(a, m), m
+	oldCoefficient, coefficient := 1, 0
+	for remainder != 0 {
+		quotient := oldRemainder / remainder
+		oldRemainder, remainder = remainder, oldRemainder-quotient*remainder
+		oldCoefficient, coefficient = coefficient, oldCoefficient-quotient*coefficient
+	}
+	if oldRemainder != 1 {
+		return 0, false
+	}
+	return mod(oldCoefficient, m), true
+}
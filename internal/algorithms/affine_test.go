@@ -0,0 +1,85 @@
+package algorithms
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NewAffineRuneFunc(t *testing.T) {
+	// given
+	key := AffineKey{5, 8}
+	latinAlphabets := namedAlphabets[LatinAlphabetsName]
+	input := []rune("Affine Cipher!")
+	expected := []rune("Ihhwvc Swfrcp!")
+	encodeFunc, encodeErr := NewAffineRuneFunc(key, true, latinAlphabets...)
+	decodeFunc, decodeErr := NewAffineRuneFunc(key, false, latinAlphabets...)
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	// when & then
+	mirrorSlice(input, encodeFunc)
+	assert.Equal(t, string(expected), string(input))
+	mirrorSlice(input, decodeFunc)
+	assert.Equal(t, "Affine Cipher!", string(input))
+}
+
+func Test_NewAffineRuneFunc_caesar(t *testing.T) {
+	// given
+	affineFunc, err := NewAffineRuneFunc(AffineKey{1, -3}, true, Digits, LatinLowercase)
+	assert.NoError(t, err)
+	caesarFunc := NewAlphabetOffsetRuneFunc(3, false, Digits, LatinLowercase)
+	for _, r := range "0123456789 abcdefghijklmnopqrstuvwxyz" {
+		// when
+		result := affineFunc(r)
+		// then
+		assert.Equal(t, caesarFunc(r), result, string(r))
+	}
+}
+
+func Test_NewAffineRuneFunc_invalidKey(t *testing.T) {
+	// given
+	keys := []AffineKey{{13, 8}, {5, 8}, {1, 0}}
+	alphabets := [][]*Alphabet{{LatinLowercase}, {LatinLowercase, Digits}, {LatinLowercase}}
+	expectedErrs := []error{
+		&ErrInvalidKey{"13:8", "multiplier: 13 must be coprime with the alphabet size: 26"},
+		&ErrInvalidKey{"5:8", "multiplier: 5 must be coprime with the alphabet size: 10"},
+		&ErrInvalidKey{"1:0", "multiplier of 1 with zero offset does not do anything"},
+	}
+	for i, key := range keys {
+		// when
+		affineFunc, err := NewAffineRuneFunc(key, true, alphabets[i]...)
+		// then
+		assert.Nil(t, affineFunc, key)
+		assert.Equal(t, expectedErrs[i], err, key)
+	}
+}
+
+func Test_ParseAffineKey(t *testing.T) {
+	// given
+	inputs := []string{"5:8", " 7 : -1 ", "5,8", " 3 , 2", "5", "5:", "a:8", "5;8"}
+	expectedKeys := []AffineKey{{5, 8}, {7, -1}, {5, 8}, {3, 2}, {}, {}, {}, {}}
+	for i, input := range inputs {
+		// when
+		key, err := ParseAffineKey(input)
+		// then
+		assert.Equal(t, expectedKeys[i], key, input)
+		if i < 4 {
+			assert.NoError(t, err, input)
+		} else {
+			assert.IsType(t, &ErrInvalidKey{}, err, input)
+		}
+	}
+}
+
+func Test_modInverse(t *testing.T) {
+	// given
+	inputs := [][2]int{{5, 26}, {-5, 26}, {3, 10}, {1, 2}, {13, 26}, {0, 10}}
+	expectedInverses := []int{21, 5, 7, 1, 0, 0}
+	expectedOks := []bool{true, true, true, true, false, false}
+	for i, input := range inputs {
+		// when
+		inverse, ok := modInverse(input[0], input[1])
+		// then
+		assert.Equal(t, expectedInverses[i], inverse, input)
+		assert.Equal(t, expectedOks[i], ok, input)
+	}
+}
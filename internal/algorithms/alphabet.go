@@ -0,0 +1,129 @@
+package algorithms
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Alphabet is an ordered set of runes, within which the alphabet-restricted ciphers operate.
+type Alphabet struct {
+	runes   []rune
+	indices map[rune]int
+}
+
+func NewAlphabet(letters string) (*Alphabet, error) {
+	runes := []rune(letters)
+	if len(runes) < 2 {
+		return nil, &ErrInvalidAlphabet{letters, "alphabet must consist of at least two runes"}
+	}
+	indices := make(map[rune]int, len(runes))
+	for i, r := range runes {
+		if _, ok := indices[r]; ok {
+			return nil, &ErrInvalidAlphabet{letters, fmt.Sprintf("rune: %q occurs more than once", r)}
+		}
+		indices[r] = i
+	}
+	return &Alphabet{runes, indices}, nil
+}
+
+func mustNewAlphabet(letters string) *Alphabet {
+	alphabet, err := NewAlphabet(letters)
+	if err != nil {
+		panic(err)
+	}
+	return alphabet
+}
+
+func (alphabet *Alphabet) Size() int {
+	return len(alphabet.runes)
+}
+
+// String returns the runes of the alphabet in their order.
+func (alphabet *Alphabet) String() string {
+	return string(alphabet.runes)
+}
+
+func (alphabet *Alphabet) index(r rune) (int, bool) {
+	i, ok := alphabet.indices[r]
+	return i, ok
+}
+
+func (alphabet *Alphabet) rune(i int) rune {
+	return alphabet.runes[i]
+}
+
+var (
+	LatinLowercase    = mustNewAlphabet("abcdefghijklmnopqrstuvwxyz")
+	LatinUppercase    = mustNewAlphabet("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	CyrillicLowercase = mustNewAlphabet("абвгдеёжзийклмнопрстуфхцчшщъыьэюя")
+	CyrillicUppercase = mustNewAlphabet("АБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯ")
+	GreekLowercase    = mustNewAlphabet("αβγδεζηθικλμνξοπρστυφχψω")
+	GreekUppercase    = mustNewAlphabet("ΑΒΓΔΕΖΗΘΙΚΛΜΝΞΟΠΡΣΤΥΦΧΨΩ")
+	Digits            = mustNewAlphabet("0123456789")
+)
+
+const (
+	LatinAlphabetsName    = "latin"
+	CyrillicAlphabetsName = "cyrillic"
+	GreekAlphabetsName    = "greek"
+	DigitsAlphabetsName   = "digits"
+)
+
+// AlphabetsNameSeparator joins the names of the predefined alphabet sets into one, e.g. latin+digits.
+const AlphabetsNameSeparator = "+"
+
+// namedAlphabets maps the names accepted by GetAlphabets to the alphabets they stand for. Letter cases
+// are kept as separate alphabets, so that the ciphers preserve them.
+var namedAlphabets = map[string][]*Alphabet{
+	LatinAlphabetsName:    {LatinLowercase, LatinUppercase},
+	CyrillicAlphabetsName: {CyrillicLowercase, CyrillicUppercase},
+	GreekAlphabetsName:    {GreekLowercase, GreekUppercase},
+	DigitsAlphabetsName:   {Digits},
+}
+
+// GetAlphabets resolves either the names of the predefined alphabet sets joined with the
+// AlphabetsNameSeparator or a custom alphabet given literally as the runes it consists of.
+func GetAlphabets(nameOrLetters string) ([]*Alphabet, error) {
+	if alphabets, ok := getNamedAlphabets(nameOrLetters); ok {
+		return alphabets, nil
+	}
+	alphabet, err := NewAlphabet(nameOrLetters)
+	if err != nil {
+		return nil, err
+	}
+	return []*Alphabet{alphabet}, nil
+}
+
+func getNamedAlphabets(names string) ([]*Alphabet, bool) {
+	var alphabets []*Alphabet
+	for _, name := range strings.Split(names, AlphabetsNameSeparator) {
+		namedAlphabet, ok := namedAlphabets[name]
+		if !ok {
+			return nil, false
+		}
+		alphabets = append(alphabets, namedAlphabet...)
+	}
+	return alphabets, true
+}
+
+func findAlphabet(alphabets []*Alphabet, r rune) (*Alphabet, int, bool) {
+	for _, alphabet := range alphabets {
+		if i, ok := alphabet.index(r); ok {
+			return alphabet, i, true
+		}
+	}
+	return nil, -1, false
+}
+
+func mod(a int, m int) int {
+	return ((a % m) + m) % m
+}
+
+type ErrInvalidAlphabet struct {
+	Alphabet string
+	Reason   string
+}
+
+func (e *ErrInvalidAlphabet) Error() string {
+	return fmt.Sprintf("invalid alphabet: %s, %s", e.Alphabet, e.Reason)
+}
@@ -0,0 +1,64 @@
+package algorithms
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_GetAlphabets_named(t *testing.T) {
+	// given
+	input := LatinAlphabetsName
+	expected := []*Alphabet{LatinLowercase, LatinUppercase}
+	// when
+	result, err := GetAlphabets(input)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func Test_GetAlphabets_joinedNames(t *testing.T) {
+	// given
+	input := GreekAlphabetsName + AlphabetsNameSeparator + DigitsAlphabetsName
+	expected := []*Alphabet{GreekLowercase, GreekUppercase, Digits}
+	// when
+	result, err := GetAlphabets(input)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func Test_GetAlphabets_customWithSeparator(t *testing.T) {
+	// given
+	input := "latin+x"
+	expectedSize := 7
+	// when
+	result, err := GetAlphabets(input)
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, expectedSize, result[0].Size())
+}
+
+func Test_GetAlphabets_custom(t *testing.T) {
+	// given
+	input := "0123456789"
+	expectedSize := 10
+	// when
+	result, err := GetAlphabets(input)
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, expectedSize, result[0].Size())
+}
+
+func Test_GetAlphabets_invalid(t *testing.T) {
+	// given
+	inputs := []string{"", "a", "abca"}
+	for _, input := range inputs {
+		// when
+		result, err := GetAlphabets(input)
+		// then
+		assert.Nil(t, result)
+		assert.IsType(t, &ErrInvalidAlphabet{}, err)
+	}
+}
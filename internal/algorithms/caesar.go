@@ -1,6 +1,10 @@
 package algorithms
 
-import "unicode"
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
 
 func caesarSlice(input []rune, caesarFunc func(rune) rune) {
 	for i, r := range input {
@@ -21,18 +25,81 @@ func NewOffsetRuneFunc(offset int32) func(rune) rune {
 	panic("zero offset does not do anything")
 }
 
+// NewOffsetByteFunc shifts the bytes modulo 256, which makes it applicable to any binary data. The offset
+// is reduced modulo 256 first, so that no offset overflows.
+func NewOffsetByteFunc(offset int) func(byte) byte {
+	shift := byte(mod(offset, math.MaxUint8+1))
+	return func(b byte) byte {
+		return b + shift
+	}
+}
+
+// The Caesar code space consists of the Unicode scalar values only, that is every code point up to
+// unicode.MaxRune except for the UTF-16 surrogates. Shifting never lands on a surrogate, so each shifted
+// rune can be encoded as UTF-8, and the decoding restores the original one.
+const (
+	surrogateMin    = 0xD800
+	surrogateMax    = 0xDFFF
+	surrogatesCount = surrogateMax - surrogateMin + 1
+	scalarValues    = unicode.MaxRune + 1 - surrogatesCount
+)
+
+// ScalarValues is the size of the Caesar code space, the offsets equal modulo which shift alike.
+const ScalarValues = scalarValues
+
 func offsetRuneForward(r rune, offset int32) rune {
-	distance := unicode.MaxRune - r
-	if distance < offset {
-		return offset - distance - 1
+	if !utf8.ValidRune(r) {
+		return r
 	}
-	return r + offset
+	return scalarValueRune((runeScalarValue(r) + offset%scalarValues) % scalarValues)
 }
 
 func offsetRuneBackward(r rune, offset int32) rune {
-	distance := r
-	if distance < offset {
-		return unicode.MaxRune - (offset - distance - 1)
+	if !utf8.ValidRune(r) {
+		return r
+	}
+	return scalarValueRune((runeScalarValue(r) - offset%scalarValues + scalarValues) % scalarValues)
+}
+
+// OffsetBetween returns the offset, within the range (-scalarValues/2, scalarValues/2], by which
+// NewOffsetRuneFunc shifts the valid rune from onto the valid rune to.
+func OffsetBetween(from rune, to rune) int32 {
+	offset := (runeScalarValue(to) - runeScalarValue(from) + scalarValues) % scalarValues
+	if offset > scalarValues/2 {
+		offset -= scalarValues
+	}
+	return offset
+}
+
+// runeScalarValue returns the index of the rune in the code space with the surrogates removed.
+func runeScalarValue(r rune) int32 {
+	if r > surrogateMax {
+		return r - surrogatesCount
+	}
+	return r
+}
+
+func scalarValueRune(index int32) rune {
+	if index >= surrogateMin {
+		return index + surrogatesCount
+	}
+	return index
+}
+
+// NewAlphabetOffsetRuneFunc rotates the runes within the first of the alphabets that contains them,
+// leaving any other rune untouched, backwards unless forward. With the Latin alphabets and the offset of
+// 13 it is ROT13. The offset is reduced modulo the alphabet size before it is added or subtracted, so
+// that any offset, math.MinInt included, neither overflows nor breaks the decoding.
+func NewAlphabetOffsetRuneFunc(offset int, forward bool, alphabets ...*Alphabet) func(rune) rune {
+	return func(r rune) rune {
+		alphabet, i, ok := findAlphabet(alphabets, r)
+		if !ok {
+			return r
+		}
+		shift := mod(offset, alphabet.Size())
+		if !forward {
+			shift = alphabet.Size() - shift
+		}
+		return alphabet.rune((i + shift) % alphabet.Size())
 	}
-	return r - offset
 }
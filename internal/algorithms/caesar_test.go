@@ -3,8 +3,10 @@ package algorithms
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 	"unicode"
+	"unicode/utf8"
 )
 
 func Test_caesarSlice(t *testing.T) {
@@ -44,3 +46,156 @@ func Test_offsetRuneBackward(t *testing.T) {
 	fmt.Println(string(output))
 	// then
 }
+
+func Test_NewAlphabetOffsetRuneFunc(t *testing.T) {
+	// given
+	input := []rune("Hello, World! xyz 123")
+	expected := []rune("Khoor, Zruog! abc 123")
+	// when
+	caesarSlice(input, NewAlphabetOffsetRuneFunc(3, true, LatinLowercase, LatinUppercase))
+	// then
+	assert.Equal(t, expected, input)
+}
+
+func Test_NewAlphabetOffsetRuneFunc_rot13(t *testing.T) {
+	// given
+	input := []rune("Why did the chicken cross the road?")
+	expected := []rune("Jul qvq gur puvpxra pebff gur ebnq?")
+	// when
+	caesarSlice(input, NewAlphabetOffsetRuneFunc(13, true, LatinLowercase, LatinUppercase))
+	// then
+	assert.Equal(t, expected, input)
+}
+
+func Test_NewAlphabetOffsetRuneFunc_roundTrip(t *testing.T) {
+	// given
+	input := []rune("Zażółć gęślą jaźń, 42!")
+	expected := []rune("Zażółć gęślą jaźń, 42!")
+	var offset = 55
+	// when
+	caesarSlice(input, NewAlphabetOffsetRuneFunc(offset, true, LatinLowercase, LatinUppercase))
+	caesarSlice(input, NewAlphabetOffsetRuneFunc(offset, false, LatinLowercase, LatinUppercase))
+	// then
+	assert.Equal(t, expected, input)
+}
+
+func Test_NewAlphabetOffsetRuneFunc_extremeOffsets(t *testing.T) {
+	// given
+	offsets := []int{math.MaxInt, math.MinInt, math.MaxInt - 25}
+	for _, offset := range offsets {
+		input := []rune("Hello, World!")
+		// when
+		caesarSlice(input, NewAlphabetOffsetRuneFunc(offset, true, LatinLowercase, LatinUppercase))
+		caesarSlice(input, NewAlphabetOffsetRuneFunc(offset, false, LatinLowercase, LatinUppercase))
+		// then
+		assert.Equal(t, "Hello, World!", string(input), offset)
+	}
+}
+
+func Test_offsetRune_neverLandsOnSurrogate(t *testing.T) {
+	// given
+	inputs := []struct {
+		r      rune
+		offset int32
+	}{
+		{0xD7FF, 1},
+		{0xD7FE, 2},
+		{0xE000, 1},
+		{0xE001, 2},
+	}
+	expected := []struct {
+		forward  rune
+		backward rune
+	}{
+		{0xE000, 0xD7FE},
+		{0xE000, 0xD7FC},
+		{0xE001, 0xD7FF},
+		{0xE003, 0xD7FF},
+	}
+	for i, input := range inputs {
+		// when
+		forward := offsetRuneForward(input.r, input.offset)
+		backward := offsetRuneBackward(input.r, input.offset)
+		// then
+		assert.Equal(t, expected[i].forward, forward)
+		assert.Equal(t, expected[i].backward, backward)
+	}
+}
+
+func Test_offsetRune_wrapsAround(t *testing.T) {
+	// given
+	var input rune = unicode.MaxRune
+	var expectedForward rune = 0
+	var expectedBackward rune = unicode.MaxRune
+	// when
+	forward := offsetRuneForward(input, 1)
+	backward := offsetRuneBackward(forward, 1)
+	// then
+	assert.Equal(t, expectedForward, forward)
+	assert.Equal(t, expectedBackward, backward)
+}
+
+func Test_NewOffsetRuneFunc_roundTripOverAllScalarValues(t *testing.T) {
+	// given
+	offsets := []int32{1, 0x800, 0xD800, 0x10000, scalarValues - 1, scalarValues + 7, unicode.MaxRune}
+	for _, offset := range offsets {
+		encodeFunc := NewOffsetRuneFunc(offset)
+		decodeFunc := NewOffsetRuneFunc(-offset)
+		for r := rune(0); r <= unicode.MaxRune; r++ {
+			if !utf8.ValidRune(r) {
+				continue
+			}
+			// when
+			encoded := encodeFunc(r)
+			decoded := decodeFunc(encoded)
+			// then
+			if !utf8.ValidRune(encoded) || decoded != r {
+				t.Fatalf("offset: %d, rune: %U, encoded: %U, decoded: %U", offset, r, encoded, decoded)
+			}
+		}
+	}
+}
+
+func Test_NewOffsetByteFunc_wrapsAround(t *testing.T) {
+	// given
+	input := []byte{0x00, 0x7F, 0xFE, 0xFF}
+	expected := []byte{0x02, 0x81, 0x00, 0x01}
+	// when
+	transformBytes(input, NewOffsetByteFunc(258))
+	// then
+	assert.Equal(t, expected, input)
+	transformBytes(input, NewOffsetByteFunc(-258))
+	assert.Equal(t, []byte{0x00, 0x7F, 0xFE, 0xFF}, input)
+}
+
+func Test_NewOffsetByteFunc_extremeOffsets(t *testing.T) {
+	// given
+	offsets := []int{math.MaxInt, math.MinInt}
+	expected := [][]byte{{0xFF, 0x7E, 0xFD, 0xFE}, {0x00, 0x7F, 0xFE, 0xFF}}
+	for i, offset := range offsets {
+		input := []byte{0x00, 0x7F, 0xFE, 0xFF}
+		// when
+		transformBytes(input, NewOffsetByteFunc(offset))
+		// then
+		assert.Equal(t, expected[i], input, offset)
+	}
+}
+
+func transformBytes(input []byte, transformFunc func(byte) byte) {
+	for i, b := range input {
+		input[i] = transformFunc(b)
+	}
+}
+
+func Test_OffsetBetween(t *testing.T) {
+	// given
+	froms := []rune{'a', 'd', 'a', unicode.MaxRune, 0xD7FF}
+	tos := []rune{'d', 'a', unicode.MaxRune, 'a', 0xE000}
+	for i := range froms {
+		// when
+		offset := OffsetBetween(froms[i], tos[i])
+		// then
+		assert.Equal(t, tos[i], NewOffsetRuneFunc(offset)(froms[i]), i)
+		assert.LessOrEqual(t, offset, int32(scalarValues/2), i)
+	}
+}
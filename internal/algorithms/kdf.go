@@ -0,0 +1,34 @@
+package algorithms
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"hash"
+)
+
+// pbkdf2 derives the key of the keySize from the password and the salt as specified by RFC 8018, with
+// the HMAC of the newHash as the pseudorandom function.
+func pbkdf2(newHash func() hash.Hash, password []byte, salt []byte, iterations int, keySize int) []byte {
+	prf := hmac.New(newHash, password)
+	hashSize := prf.Size()
+	blockCount := (keySize + hashSize - 1) / hashSize
+	key := make([]byte, 0, blockCount*hashSize)
+	u := make([]byte, 0, hashSize)
+	for block := uint32(1); block <= uint32(blockCount); block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u = prf.Sum(u[:0])
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keySize]
+}
@@ -0,0 +1,26 @@
+package algorithms
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_pbkdf2(t *testing.T) {
+	// given
+	iterations := []int{1, 2, 4096}
+	keySizes := []int{32, 32, 40}
+	expectedKeys := []string{
+		"120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b",
+		"ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43",
+		"c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134af7ad98c1b458ce3f",
+	}
+	for i := range iterations {
+		// when
+		key := pbkdf2(sha256.New, []byte("password"), []byte("salt"), iterations[i], keySizes[i])
+		// then
+		assert.Len(t, key, keySizes[i])
+		assert.Equal(t, expectedKeys[i], hex.EncodeToString(key), iterations[i])
+	}
+}
@@ -3,6 +3,29 @@ package algorithms
 import (
 	"math"
 	"unicode"
+	"unicode/utf8"
+)
+
+// MirrorDomain is the range of runes the mirror reverses, the runes outside of it are rejected.
+type MirrorDomain string
+
+const (
+	ScalarDomain MirrorDomain = "scalar" // Every Unicode scalar value, so any UTF-8 text.
+	BMPDomain    MirrorDomain = "bmp"    // The scalar values of the Basic Multilingual Plane.
+	Latin1Domain MirrorDomain = "latin1" // The runes up to unicode.MaxLatin1.
+)
+
+// MirrorDomains lists all the domains, the first one being the default.
+var MirrorDomains = []MirrorDomain{ScalarDomain, BMPDomain, Latin1Domain}
+
+// RejectedRune is returned by the transform functions for the runes they cannot transform. Being an
+// invalid rune, it is passed through untouched by the other functions, and the transformer reports it.
+const RejectedRune rune = -1
+
+// bmpScalarValues is the number of the scalar values of the BMP, which has all the surrogates in it.
+const (
+	maxBMP          = 0xFFFF
+	bmpScalarValues = maxBMP + 1 - surrogatesCount
 )
 
 func mirrorSlice[T comparable](input []T, mirrorFunc func(T) T) {
@@ -11,17 +34,82 @@ func mirrorSlice[T comparable](input []T, mirrorFunc func(T) T) {
 	}
 }
 
+// NewMirrorRuneFunc returns the function reversing the order of the runes of the domain. Mirroring is
+// its own inverse, so the same function decodes.
+func NewMirrorRuneFunc(domain MirrorDomain) (func(rune) rune, error) {
+	switch domain {
+	case ScalarDomain:
+		return getMirrorRuneScalar, nil
+	case BMPDomain:
+		return getMirrorRuneBMP, nil
+	case Latin1Domain:
+		return GetMirrorRuneLatin1, nil
+	default:
+		return nil, &ErrUnknownMirrorDomain{string(domain)}
+	}
+}
+
+// NewAlphabetMirrorRuneFunc reverses the order of the runes within the first of the alphabets containing
+// them, rejecting the runes of none of them.
+func NewAlphabetMirrorRuneFunc(alphabets ...*Alphabet) func(rune) rune {
+	return func(r rune) rune {
+		mirroredRune, ok := mirrorInAlphabets(alphabets, r)
+		if !ok {
+			return RejectedRune
+		}
+		return mirroredRune
+	}
+}
+
+// NewAtbashRuneFunc works like NewAlphabetMirrorRuneFunc, but leaves the runes of none of the alphabets
+// untouched, so that the text stays readable. With the Latin alphabets it is the classic Atbash.
+func NewAtbashRuneFunc(alphabets ...*Alphabet) func(rune) rune {
+	return func(r rune) rune {
+		mirroredRune, _ := mirrorInAlphabets(alphabets, r)
+		return mirroredRune
+	}
+}
+
+// mirrorInAlphabets returns the rune untouched, unless one of the alphabets contains it.
+func mirrorInAlphabets(alphabets []*Alphabet, r rune) (rune, bool) {
+	alphabet, i, ok := findAlphabet(alphabets, r)
+	if !ok {
+		return r, false
+	}
+	return alphabet.rune(alphabet.Size() - 1 - i), true
+}
+
 func GetMirrorRuneLatin1(r rune) rune {
-	if r > unicode.MaxLatin1 {
-		panic("incorrect rune provided, at most can be 255")
+	if r < 0 || r > unicode.MaxLatin1 {
+		return RejectedRune
 	}
 	return unicode.MaxLatin1 - r
 }
 
-func getMirrorRune(r rune) rune {
-	return unicode.MaxRune - r
+// getMirrorRuneBMP mirrors the BMP with the surrogates removed, so that no rune lands on a surrogate.
+func getMirrorRuneBMP(r rune) rune {
+	if !utf8.ValidRune(r) || r > maxBMP {
+		return RejectedRune
+	}
+	return scalarValueRune(bmpScalarValues - 1 - runeScalarValue(r))
 }
 
-func getMirrorByte(b byte) byte {
+// getMirrorRuneScalar mirrors the whole code space with the surrogates removed, just like the Caesar one.
+func getMirrorRuneScalar(r rune) rune {
+	if !utf8.ValidRune(r) {
+		return RejectedRune
+	}
+	return scalarValueRune(scalarValues - 1 - runeScalarValue(r))
+}
+
+func GetMirrorByte(b byte) byte {
 	return math.MaxUint8 - b
 }
+
+type ErrUnknownMirrorDomain struct {
+	Domain string
+}
+
+func (e *ErrUnknownMirrorDomain) Error() string {
+	return "unknown mirror domain: " + e.Domain
+}
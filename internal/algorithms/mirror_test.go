@@ -3,6 +3,8 @@ package algorithms
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"unicode"
+	"unicode/utf8"
 )
 
 func Test_mirrorRuneSlice(t *testing.T) {
@@ -15,3 +17,103 @@ func Test_mirrorRuneSlice(t *testing.T) {
 	// then
 	assert.Equal(t, expected, input)
 }
+
+func Test_mirrorByteSlice(t *testing.T) {
+	// given
+	input := []byte{0x00, 0x80, 0xFF}
+	expected := []byte{0xFF, 0x7F, 0x00}
+	// when
+	mirrorSlice(input, GetMirrorByte)
+	// then
+	assert.Equal(t, expected, input)
+}
+
+func Test_NewMirrorRuneFunc_roundTrip(t *testing.T) {
+	// given
+	inputs := map[MirrorDomain][]rune{
+		ScalarDomain: {0, 'a', 'ł', surrogateMin - 1, surrogateMax + 1, 0xFFFF, '𝄞', unicode.MaxRune},
+		BMPDomain:    {0, 'a', 'ł', surrogateMin - 1, surrogateMax + 1, 0xFFFF},
+		Latin1Domain: {0, 'a', 'ó', unicode.MaxLatin1},
+	}
+	for domain, runes := range inputs {
+		mirrorFunc, err := NewMirrorRuneFunc(domain)
+		assert.NoError(t, err)
+		for _, r := range runes {
+			// when
+			mirrored := mirrorFunc(r)
+			// then
+			assert.True(t, utf8.ValidRune(mirrored), "%s %U", domain, r)
+			assert.Equal(t, r, mirrorFunc(mirrored), "%s %U", domain, r)
+		}
+	}
+}
+
+func Test_NewMirrorRuneFunc_bounds(t *testing.T) {
+	// given
+	domains := []MirrorDomain{ScalarDomain, ScalarDomain, BMPDomain, BMPDomain, Latin1Domain}
+	inputs := []rune{0, 1, 0, surrogateMax + 1, 'a'}
+	expected := []rune{unicode.MaxRune, unicode.MaxRune - 1, 0xFFFF, 0x1FFF, '\u009E'}
+	for i, domain := range domains {
+		mirrorFunc, _ := NewMirrorRuneFunc(domain)
+		// when
+		result := mirrorFunc(inputs[i])
+		// then
+		assert.Equal(t, expected[i], result, "%s %U", domain, inputs[i])
+	}
+}
+
+func Test_NewMirrorRuneFunc_rejectedRunes(t *testing.T) {
+	// given
+	domains := []MirrorDomain{ScalarDomain, ScalarDomain, BMPDomain, Latin1Domain, Latin1Domain}
+	inputs := []rune{surrogateMin, RejectedRune, '𝄞', 'ł', RejectedRune}
+	for i, domain := range domains {
+		mirrorFunc, _ := NewMirrorRuneFunc(domain)
+		// when
+		result := mirrorFunc(inputs[i])
+		// then
+		assert.Equal(t, RejectedRune, result, "%s %U", domain, inputs[i])
+	}
+}
+
+func Test_NewMirrorRuneFunc_unknownDomain(t *testing.T) {
+	// when
+	mirrorFunc, err := NewMirrorRuneFunc("ascii")
+	// then
+	assert.Nil(t, mirrorFunc)
+	assert.Equal(t, &ErrUnknownMirrorDomain{"ascii"}, err)
+}
+
+func Test_NewAlphabetMirrorRuneFunc(t *testing.T) {
+	// given
+	mirrorFunc := NewAlphabetMirrorRuneFunc(LatinLowercase, LatinUppercase)
+	inputs := []rune{'a', 'z', 'M', 'ł', ' '}
+	expected := []rune{'z', 'a', 'N', RejectedRune, RejectedRune}
+	for i, input := range inputs {
+		// when
+		result := mirrorFunc(input)
+		// then
+		assert.Equal(t, expected[i], result, string(input))
+	}
+}
+
+func Test_NewAtbashRuneFunc(t *testing.T) {
+	// given
+	atbashFunc := NewAtbashRuneFunc(namedAlphabets[LatinAlphabetsName]...)
+	input := []rune("Attack at dawn, 1 ł!")
+	expected := []rune("Zggzxp zg wzdm, 1 ł!")
+	// when
+	mirrorSlice(input, atbashFunc)
+	// then
+	assert.Equal(t, string(expected), string(input))
+}
+
+func Test_NewAtbashRuneFunc_alphabets(t *testing.T) {
+	// given
+	atbashFunc := NewAtbashRuneFunc(CyrillicLowercase, CyrillicUppercase, GreekLowercase, GreekUppercase, Digits)
+	input := []rune("Привет Ωμέγα 2024")
+	expected := []rune("Поцэъм Ανέχω 7975")
+	// when
+	mirrorSlice(input, atbashFunc)
+	// then
+	assert.Equal(t, string(expected), string(input))
+}
@@ -0,0 +1,73 @@
+package algorithms
+
+import (
+	"fmt"
+)
+
+// NewSubstitutionRuneFunc returns the function substituting the runes of the alphabets according to the
+// key, leaving the other runes untouched. The key is either a full permutation of the alphabet, or a
+// keyword, whose distinct runes are followed by the remaining runes of the alphabet in their order, so
+// that ZEBRAS stands for ZEBRASCDFGHIJKLMNOPQTUVWXY. The alphabets have to be of the same size, as the
+// key permutes the indices within them, which keeps the letter cases.
+func NewSubstitutionRuneFunc(key string, forward bool, alphabets ...*Alphabet) (func(rune) rune, error) {
+	permutation, err := newSubstitutionPermutation(key, alphabets)
+	if err != nil {
+		return nil, err
+	}
+	if !forward {
+		permutation = invertPermutation(permutation)
+	}
+	return func(r rune) rune {
+		alphabet, i, ok := findAlphabet(alphabets, r)
+		if !ok {
+			return r
+		}
+		return alphabet.rune(permutation[i])
+	}, nil
+}
+
+func newSubstitutionPermutation(key string, alphabets []*Alphabet) ([]int, error) {
+	if len(alphabets) == 0 {
+		return nil, &ErrInvalidKey{key, "there is no alphabet to substitute within"}
+	}
+	size := alphabets[0].Size()
+	for _, alphabet := range alphabets[1:] {
+		if alphabet.Size() != size {
+			return nil, &ErrInvalidAlphabet{alphabet.String(), fmt.Sprintf("alphabet must be of the size: %d of the others", size)}
+		}
+	}
+	keyRunes := []rune(key)
+	if len(keyRunes) == 0 {
+		return nil, &ErrInvalidKey{key, "key must not be empty"}
+	}
+	isPermutation := len(keyRunes) == size
+	permutation := make([]int, 0, size)
+	used := make([]bool, size)
+	for _, r := range keyRunes {
+		_, i, ok := findAlphabet(alphabets, r)
+		switch {
+		case !ok:
+			return nil, &ErrInvalidKey{key, fmt.Sprintf("key must consist of the runes of the alphabet, got: %q", r)}
+		case used[i] && isPermutation:
+			return nil, &ErrInvalidKey{key, fmt.Sprintf("key of the alphabet size must be its permutation, but: %q repeats", r)}
+		case used[i]:
+			continue
+		}
+		used[i] = true
+		permutation = append(permutation, i)
+	}
+	for i, isUsed := range used {
+		if !isUsed {
+			permutation = append(permutation, i)
+		}
+	}
+	return permutation, nil
+}
+
+func invertPermutation(permutation []int) []int {
+	inverse := make([]int, len(permutation))
+	for i, j := range permutation {
+		inverse[j] = i
+	}
+	return inverse
+}
@@ -0,0 +1,82 @@
+package algorithms
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NewSubstitutionRuneFunc_keyword(t *testing.T) {
+	// given
+	latinAlphabets := namedAlphabets[LatinAlphabetsName]
+	input := []rune("abcdefghijklmnopqrstuvwxyz Flee at once!")
+	expected := []rune("zebrascdfghijklmnopqtuvwxy Siaa zq lkba!")
+	encodeFunc, encodeErr := NewSubstitutionRuneFunc("ZEBRAS", true, latinAlphabets...)
+	decodeFunc, decodeErr := NewSubstitutionRuneFunc("ZEBRAS", false, latinAlphabets...)
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	// when & then
+	mirrorSlice(input, encodeFunc)
+	assert.Equal(t, string(expected), string(input))
+	mirrorSlice(input, decodeFunc)
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz Flee at once!", string(input))
+}
+
+func Test_NewSubstitutionRuneFunc_permutation(t *testing.T) {
+	// given
+	substitutionFunc, err := NewSubstitutionRuneFunc("9876543210", true, Digits)
+	assert.NoError(t, err)
+	input := []rune("2024-10-18")
+	expected := []rune("7975-89-81")
+	// when
+	mirrorSlice(input, substitutionFunc)
+	// then
+	assert.Equal(t, string(expected), string(input))
+}
+
+func Test_newSubstitutionPermutation(t *testing.T) {
+	// given
+	keys := []string{"ZEBRAS", "zebras", "hello", "ba"}
+	expectedPermutations := [][]int{
+		{25, 4, 1, 17, 0, 18, 2, 3, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 19, 20, 21, 22, 23, 24},
+		{25, 4, 1, 17, 0, 18, 2, 3, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 19, 20, 21, 22, 23, 24},
+		{7, 4, 11, 14, 0, 1, 2, 3, 5, 6, 8, 9, 10, 12, 13, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25},
+		{1, 0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25},
+	}
+	for i, key := range keys {
+		// when
+		permutation, err := newSubstitutionPermutation(key, []*Alphabet{LatinLowercase, LatinUppercase})
+		// then
+		assert.NoError(t, err, key)
+		assert.Equal(t, expectedPermutations[i], permutation, key)
+	}
+}
+
+func Test_newSubstitutionPermutation_invalid(t *testing.T) {
+	// given
+	keys := []string{"", "zebra!", "0123456788", "12", "12"}
+	alphabets := [][]*Alphabet{{Digits}, {LatinLowercase}, {Digits}, {Digits, LatinLowercase}, {}}
+	expectedErrs := []error{
+		&ErrInvalidKey{"", "key must not be empty"},
+		&ErrInvalidKey{"zebra!", "key must consist of the runes of the alphabet, got: '!'"},
+		&ErrInvalidKey{"0123456788", "key of the alphabet size must be its permutation, but: '8' repeats"},
+		&ErrInvalidAlphabet{"abcdefghijklmnopqrstuvwxyz", "alphabet must be of the size: 10 of the others"},
+		&ErrInvalidKey{"12", "there is no alphabet to substitute within"},
+	}
+	for i, key := range keys {
+		// when
+		permutation, err := newSubstitutionPermutation(key, alphabets[i])
+		// then
+		assert.Nil(t, permutation, key)
+		assert.Equal(t, expectedErrs[i], err, key)
+	}
+}
+
+func Test_invertPermutation(t *testing.T) {
+	// given
+	permutation := []int{2, 0, 3, 1}
+	expected := []int{1, 3, 0, 2}
+	// when
+	inverse := invertPermutation(permutation)
+	// then
+	assert.Equal(t, expected, inverse)
+}
@@ -0,0 +1,117 @@
+package algorithms
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// DoubleTranspositionKeySeparators separate the keys of the two columnar transpositions, e.g. ZEBRAS,STRIPE.
+// Within a stage of a pipeline, where the comma separates the stages, it is the colon, e.g. double:ZEBRAS:STRIPE.
+const DoubleTranspositionKeySeparators = ",:"
+
+// PermutationFunc returns, for the block of the given length, the indices of its runes in the order they
+// are written to the output.
+type PermutationFunc func(length int) []int
+
+// NewRailFencePermutationFunc writes the runes in a zigzag over the rails and reads them rail by rail.
+func NewRailFencePermutationFunc(rails int) (PermutationFunc, error) {
+	if rails < 2 {
+		return nil, &ErrInvalidKey{strconv.Itoa(rails), "there have to be at least 2 rails"}
+	}
+	cycle := 2 * (rails - 1)
+	return func(length int) []int {
+		railIndices := make([][]int, min(rails, length)) // A block shorter than the rails does not reach the lower ones.
+		for i := range length {
+			rail := i % cycle
+			if rail >= rails {
+				rail = cycle - rail
+			}
+			railIndices[rail] = append(railIndices[rail], i)
+		}
+		return slices.Concat(railIndices...)
+	}, nil
+}
+
+// NewColumnarPermutationFunc writes the runes row by row under the runes of the key, and reads them column
+// by column in the alphabetical order of the key runes, the equal ones being read from left to right.
+func NewColumnarPermutationFunc(key string) (PermutationFunc, error) {
+	keyRunes := []rune(key)
+	if len(keyRunes) < 2 {
+		return nil, &ErrInvalidKey{key, "key must consist of at least two runes"}
+	}
+	columns := make([]int, len(keyRunes))
+	for i := range columns {
+		columns[i] = i
+	}
+	slices.SortStableFunc(columns, func(i int, j int) int {
+		return int(keyRunes[i]) - int(keyRunes[j])
+	})
+	return func(length int) []int {
+		permutation := make([]int, 0, length)
+		for _, column := range columns {
+			for i := column; i < length; i += len(columns) {
+				permutation = append(permutation, i)
+			}
+		}
+		return permutation
+	}, nil
+}
+
+// NewDoubleTranspositionPermutationFunc applies the columnar transposition with the first key, and then
+// the one with the second key, the keys being separated with one of the DoubleTranspositionKeySeparators.
+func NewDoubleTranspositionPermutationFunc(keys string) (PermutationFunc, error) {
+	firstKey, secondKey, ok := cutAny(keys, DoubleTranspositionKeySeparators)
+	if !ok {
+		return nil, &ErrInvalidKey{keys, "key must be the two keys separated with a comma or a colon, e.g. ZEBRAS,STRIPE"}
+	}
+	firstPermutationFunc, err := NewColumnarPermutationFunc(firstKey)
+	if err != nil {
+		return nil, err
+	}
+	secondPermutationFunc, err := NewColumnarPermutationFunc(secondKey)
+	if err != nil {
+		return nil, err
+	}
+	return func(length int) []int {
+		firstPermutation := firstPermutationFunc(length)
+		permutation := secondPermutationFunc(length)
+		for i, j := range permutation {
+			permutation[i] = firstPermutation[j]
+		}
+		return permutation
+	}, nil
+}
+
+// NewTranspositionBlockFuncs returns the function appending the runes of the block to the output in the
+// order given by the permutation function, and the function restoring their order. The permutation of
+// the full block is computed once, the ones of the shorter last blocks each time.
+func NewTranspositionBlockFuncs(
+	permutationFunc PermutationFunc,
+	blockSize int,
+) (encodeFunc func(output []rune, block []rune) []rune, decodeFunc func(output []rune, block []rune) []rune) {
+	getFullBlockPermutation := sync.OnceValue(func() []int {
+		return permutationFunc(blockSize)
+	})
+	getPermutation := func(length int) []int {
+		if length == blockSize {
+			return getFullBlockPermutation()
+		}
+		return permutationFunc(length)
+	}
+	encodeFunc = func(output []rune, block []rune) []rune {
+		for _, i := range getPermutation(len(block)) {
+			output = append(output, block[i])
+		}
+		return output
+	}
+	decodeFunc = func(output []rune, block []rune) []rune {
+		start := len(output)
+		output = slices.Grow(output, len(block))[:start+len(block)]
+		for i, j := range getPermutation(len(block)) {
+			output[start+j] = block[i]
+		}
+		return output
+	}
+	return encodeFunc, decodeFunc
+}
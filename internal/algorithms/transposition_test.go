@@ -0,0 +1,94 @@
+package algorithms
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const transpositionPlaintext = "WEAREDISCOVEREDFLEEATONCE"
+
+func transpose(permutationFunc PermutationFunc, input string) string {
+	encodeFunc, _ := NewTranspositionBlockFuncs(permutationFunc, len(input))
+	return string(encodeFunc(nil, []rune(input)))
+}
+
+func Test_NewRailFencePermutationFunc(t *testing.T) {
+	// given
+	rails := []int{2, 3, 4, 100}
+	expected := []string{
+		"WAEICVRDLETNEERDSOEEFEAOC",
+		"WECRLTEERDSOEEFEAOCAIVDEN",
+		"WIREEEDSEEEACAECVDLTNROFO",
+		transpositionPlaintext,
+	}
+	for i, rail := range rails {
+		permutationFunc, err := NewRailFencePermutationFunc(rail)
+		assert.NoError(t, err)
+		// when
+		result := transpose(permutationFunc, transpositionPlaintext)
+		// then
+		assert.Equal(t, expected[i], result, rail)
+	}
+}
+
+func Test_NewColumnarPermutationFunc(t *testing.T) {
+	// given
+	permutationFunc, err := NewColumnarPermutationFunc("ZEBRAS")
+	assert.NoError(t, err)
+	expected := "EVLNACDTESEAROFODEECWIREE"
+	// when
+	result := transpose(permutationFunc, transpositionPlaintext)
+	// then
+	assert.Equal(t, expected, result)
+}
+
+func Test_NewColumnarPermutationFunc_repeatedKeyRunes(t *testing.T) {
+	// given
+	permutationFunc, err := NewColumnarPermutationFunc("BAB")
+	assert.NoError(t, err)
+	expected := "beadcf"
+	// when
+	result := transpose(permutationFunc, "abcdef")
+	// then
+	assert.Equal(t, expected, result)
+}
+
+func Test_NewDoubleTranspositionPermutationFunc(t *testing.T) {
+	// given
+	permutationFunc, err := NewDoubleTranspositionPermutationFunc("ZEBRAS:STRIPE")
+	assert.NoError(t, err)
+	firstPermutationFunc, _ := NewColumnarPermutationFunc("ZEBRAS")
+	secondPermutationFunc, _ := NewColumnarPermutationFunc("STRIPE")
+	expected := transpose(secondPermutationFunc, transpose(firstPermutationFunc, transpositionPlaintext))
+	// when
+	result := transpose(permutationFunc, transpositionPlaintext)
+	// then
+	assert.Equal(t, expected, result)
+}
+
+func Test_transpositionPermutationFuncs_invalidKeys(t *testing.T) {
+	// given
+	_, railFenceErr := NewRailFencePermutationFunc(1)
+	_, columnarErr := NewColumnarPermutationFunc("Z")
+	_, doubleErr := NewDoubleTranspositionPermutationFunc("ZEBRAS")
+	_, doubleColumnarErr := NewDoubleTranspositionPermutationFunc("ZEBRAS:S")
+	// then
+	assert.Equal(t, &ErrInvalidKey{"1", "there have to be at least 2 rails"}, railFenceErr)
+	assert.Equal(t, &ErrInvalidKey{"Z", "key must consist of at least two runes"}, columnarErr)
+	assert.Equal(t, &ErrInvalidKey{"ZEBRAS", "key must be the two keys separated with a comma or a colon, e.g. ZEBRAS,STRIPE"}, doubleErr)
+	assert.Equal(t, &ErrInvalidKey{"S", "key must consist of at least two runes"}, doubleColumnarErr)
+}
+
+func Test_NewTranspositionBlockFuncs_roundTrip(t *testing.T) {
+	// given
+	permutationFunc, _ := NewColumnarPermutationFunc("ZEBRAS")
+	encodeFunc, decodeFunc := NewTranspositionBlockFuncs(permutationFunc, 8)
+	blocks := []string{"Zażółć g", "ęślą", ""}
+	for _, block := range blocks {
+		// when
+		encoded := encodeFunc([]rune("prefix"), []rune(block))
+		decoded := decodeFunc([]rune("prefix"), encoded[len("prefix"):])
+		// then
+		assert.Equal(t, "prefix"+block, string(decoded), block)
+	}
+}
@@ -0,0 +1,53 @@
+package algorithms
+
+import (
+	"fmt"
+)
+
+// NewVigenereRuneFunc returns a position-aware function which shifts the Latin letters by the offset
+// taken from the key letter at the given position. Runes other than Latin letters are left untouched,
+// but they still consume their key letter, so the key stays aligned with the stream position.
+func NewVigenereRuneFunc(key string, forward bool) (func(position int, r rune) rune, error) {
+	offsets, err := newVigenereOffsets(key)
+	if err != nil {
+		return nil, err
+	}
+	if !forward {
+		for i, offset := range offsets {
+			offsets[i] = -offset
+		}
+	}
+	latinAlphabets := namedAlphabets[LatinAlphabetsName]
+	return func(position int, r rune) rune {
+		alphabet, i, ok := findAlphabet(latinAlphabets, r)
+		if !ok {
+			return r
+		}
+		return alphabet.rune(mod(i+offsets[position%len(offsets)], alphabet.Size()))
+	}, nil
+}
+
+func newVigenereOffsets(key string) ([]int, error) {
+	if key == "" {
+		return nil, &ErrInvalidKey{key, "key must not be empty"}
+	}
+	latinAlphabets := namedAlphabets[LatinAlphabetsName]
+	offsets := make([]int, 0, len(key))
+	for _, r := range key {
+		_, i, ok := findAlphabet(latinAlphabets, r)
+		if !ok {
+			return nil, &ErrInvalidKey{key, fmt.Sprintf("key must consist of Latin letters only, got: %q", r)}
+		}
+		offsets = append(offsets, i)
+	}
+	return offsets, nil
+}
+
+type ErrInvalidKey struct {
+	Key    string
+	Reason string
+}
+
+func (e *ErrInvalidKey) Error() string {
+	return fmt.Sprintf("invalid key: %s, %s", e.Key, e.Reason)
+}
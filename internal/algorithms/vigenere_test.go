@@ -0,0 +1,52 @@
+package algorithms
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func applyPositionalFunc(input string, positionalFunc func(int, rune) rune) string {
+	output := []rune(input)
+	for i, r := range output {
+		output[i] = positionalFunc(i, r)
+	}
+	return string(output)
+}
+
+func Test_NewVigenereRuneFunc_encode(t *testing.T) {
+	// given
+	input := "ATTACKATDAWN"
+	expected := "LXFOPVEFRNHR"
+	// when
+	encodeFunc, err := NewVigenereRuneFunc("LEMON", true)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, applyPositionalFunc(input, encodeFunc))
+}
+
+func Test_NewVigenereRuneFunc_roundTrip(t *testing.T) {
+	// given
+	input := "Hello, World! Zażółć gęślą jaźń."
+	encodeFunc, encodeErr := NewVigenereRuneFunc("Key", true)
+	decodeFunc, decodeErr := NewVigenereRuneFunc("Key", false)
+	// when
+	encoded := applyPositionalFunc(input, encodeFunc)
+	decoded := applyPositionalFunc(encoded, decodeFunc)
+	// then
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	assert.NotEqual(t, input, encoded)
+	assert.Equal(t, input, decoded)
+}
+
+func Test_NewVigenereRuneFunc_invalidKey(t *testing.T) {
+	// given
+	inputs := []string{"", "key1", "klucz-ł"}
+	for _, input := range inputs {
+		// when
+		resultFunc, resultErr := NewVigenereRuneFunc(input, true)
+		// then
+		assert.Nil(t, resultFunc)
+		assert.IsType(t, &ErrInvalidKey{}, resultErr)
+	}
+}
@@ -0,0 +1,16 @@
+package algorithms
+
+import (
+	"math"
+	"strconv"
+)
+
+func NewXorByteFunc(key int) (func(byte) byte, error) {
+	if key < 0 || key > math.MaxUint8 {
+		return nil, &ErrInvalidKey{strconv.Itoa(key), "key must be within the byte range: 0-255"}
+	}
+	keyByte := byte(key)
+	return func(b byte) byte {
+		return b ^ keyByte
+	}, nil
+}
@@ -0,0 +1,32 @@
+package algorithms
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_NewXorByteFunc_roundTrip(t *testing.T) {
+	// given
+	input := []byte{0x00, 0x5A, 0xFF, 'a'}
+	expected := []byte{0x00, 0x5A, 0xFF, 'a'}
+	// when
+	xorFunc, err := NewXorByteFunc(0x5A)
+	transformBytes(input, xorFunc)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x5A, 0x00, 0xA5, 'a' ^ 0x5A}, input)
+	transformBytes(input, xorFunc)
+	assert.Equal(t, expected, input)
+}
+
+func Test_NewXorByteFunc_invalidKey(t *testing.T) {
+	// given
+	inputs := []int{-1, 256}
+	for _, input := range inputs {
+		// when
+		resultFunc, resultErr := NewXorByteFunc(input)
+		// then
+		assert.Nil(t, resultFunc)
+		assert.IsType(t, &ErrInvalidKey{}, resultErr)
+	}
+}
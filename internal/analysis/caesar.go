@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+)
+
+// Candidate is a key scored by the ChiSquared of the sample decoded with it. The Confidence is its share
+// of the inverse ChiSquared among all the candidates tried.
+type Candidate struct {
+	Key        int
+	ChiSquared float64
+	Confidence float64
+	Plaintext  []rune
+}
+
+// The keys tried over the whole Unicode scalar value range are those mapping one of the most frequent
+// runes of the sample onto one of the most frequent letters of the language, or onto the space.
+const (
+	frequentSampleRunes = 6
+	frequentLetters     = 6
+)
+
+// CrackCaesar ranks the keys of the Caesar cipher over the Unicode scalar values the sample may have been
+// encoded with, from the most to the least likely one.
+func CrackCaesar(sample []rune, table *FrequencyTable) []Candidate {
+	plainRunes := append(table.Letters()[:min(frequentLetters, len(table.letters))], ' ')
+	var keys []int
+	for _, sampleRune := range mostFrequentRunes(sample, frequentSampleRunes) {
+		for _, plainRune := range plainRunes {
+			key := int(algorithms.OffsetBetween(plainRune, sampleRune))
+			if key != 0 && !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return rankCandidates(sample, table, keys, func(key int) func(rune) rune {
+		return algorithms.NewOffsetRuneFunc(int32(-key))
+	})
+}
+
+// CrackAlphabetCaesar ranks all the keys of the Caesar cipher rotating within the alphabets.
+func CrackAlphabetCaesar(sample []rune, table *FrequencyTable, alphabets ...*algorithms.Alphabet) []Candidate {
+	size := 0
+	for _, alphabet := range alphabets {
+		size = max(size, alphabet.Size())
+	}
+	keys := make([]int, 0, size)
+	for key := 1; key < size; key++ {
+		keys = append(keys, key)
+	}
+	return rankCandidates(sample, table, keys, func(key int) func(rune) rune {
+		return algorithms.NewAlphabetOffsetRuneFunc(key, false, alphabets...)
+	})
+}
+
+func rankCandidates(sample []rune, table *FrequencyTable, keys []int, newDecodeFunc func(int) func(rune) rune) []Candidate {
+	candidates := make([]Candidate, 0, len(keys))
+	inverseTotal := 0.0
+	for _, key := range keys {
+		decodeFunc := newDecodeFunc(key)
+		plaintext := make([]rune, len(sample))
+		for i, r := range sample {
+			plaintext[i] = decodeFunc(r)
+		}
+		chiSquared := table.ChiSquared(plaintext)
+		inverseTotal += inverse(chiSquared)
+		candidates = append(candidates, Candidate{Key: key, ChiSquared: chiSquared, Plaintext: plaintext})
+	}
+	for i := range candidates {
+		if inverseTotal != 0 {
+			candidates[i].Confidence = inverse(candidates[i].ChiSquared) / inverseTotal
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		return cmp.Compare(a.ChiSquared, b.ChiSquared)
+	})
+	return candidates
+}
+
+// inverse keeps the perfect score finite, so that it does not take all the confidence.
+func inverse(chiSquared float64) float64 {
+	if math.IsInf(chiSquared, 1) {
+		return 0
+	}
+	return 1 / (chiSquared + 1e-9)
+}
+
+func mostFrequentRunes(text []rune, count int) []rune {
+	counts := make(map[rune]int)
+	for _, r := range text {
+		counts[r]++
+	}
+	runes := make([]rune, 0, len(counts))
+	for r := range counts {
+		runes = append(runes, r)
+	}
+	slices.SortFunc(runes, func(a, b rune) int {
+		if order := cmp.Compare(counts[b], counts[a]); order != 0 {
+			return order
+		}
+		return cmp.Compare(a, b)
+	})
+	return runes[:min(count, len(runes))]
+}
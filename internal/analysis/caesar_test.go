@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func encodeTestCorpus(corpus []rune, encodeFunc func(rune) rune) []rune {
+	encoded := make([]rune, len(corpus))
+	for i, r := range corpus {
+		encoded[i] = encodeFunc(r)
+	}
+	return encoded
+}
+
+func Test_CrackCaesar(t *testing.T) {
+	// given
+	corpora := []string{"english.txt", "polish.txt", "english.txt"}
+	tables := []*FrequencyTable{English, Polish, English}
+	keys := []int{3, -1234, 1_000_000}
+	for i, corpus := range corpora {
+		plaintext := readTestCorpus(t, corpus)
+		ciphertext := encodeTestCorpus(plaintext, algorithms.NewOffsetRuneFunc(int32(keys[i])))
+		// when
+		candidates := CrackCaesar(ciphertext, tables[i])
+		// then
+		assert.NotEmpty(t, candidates)
+		assert.Equal(t, algorithms.NewOffsetRuneFunc(int32(keys[i]))('a'), algorithms.NewOffsetRuneFunc(int32(candidates[0].Key))('a'), corpus)
+		assert.Equal(t, string(plaintext), string(candidates[0].Plaintext), corpus)
+		assert.Greater(t, candidates[0].Confidence, 0.5, corpus)
+	}
+}
+
+func Test_CrackAlphabetCaesar(t *testing.T) {
+	// given
+	plaintext := readTestCorpus(t, "english.txt")
+	alphabets := []*algorithms.Alphabet{algorithms.LatinLowercase, algorithms.LatinUppercase}
+	ciphertext := encodeTestCorpus(plaintext, algorithms.NewAlphabetOffsetRuneFunc(13, true, alphabets...))
+	// when
+	candidates := CrackAlphabetCaesar(ciphertext, English, alphabets...)
+	// then
+	assert.Len(t, candidates, 25)
+	assert.Equal(t, 13, candidates[0].Key)
+	assert.Equal(t, string(plaintext), string(candidates[0].Plaintext))
+	for i := 1; i < len(candidates); i++ {
+		assert.LessOrEqual(t, candidates[i-1].ChiSquared, candidates[i].ChiSquared)
+	}
+}
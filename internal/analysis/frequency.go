@@ -0,0 +1,177 @@
+// Package analysis recovers the keys of the classical ciphers from the ciphertext alone, by comparing the
+// letter frequencies of the candidate plaintexts with those of a language.
+package analysis
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FrequencyTable holds the relative frequencies of the lowercase letters of a language, summing up to 1.
+type FrequencyTable struct {
+	frequencies map[rune]float64
+	letters     []rune
+}
+
+// NewFrequencyTable normalizes the frequencies, given in any unit, of the letters, given in any case.
+func NewFrequencyTable(frequencies map[rune]float64) (*FrequencyTable, error) {
+	total := 0.0
+	for letter, frequency := range frequencies {
+		if !unicode.IsLetter(letter) || !(frequency > 0) || math.IsInf(frequency, 0) {
+			return nil, &ErrInvalidFrequencyTable{fmt.Sprintf("invalid frequency: %v of letter: %q", frequency, letter)}
+		}
+		total += frequency
+	}
+	if total == 0 {
+		return nil, &ErrInvalidFrequencyTable{"no letters"}
+	}
+	table := &FrequencyTable{frequencies: make(map[rune]float64, len(frequencies))}
+	for letter, frequency := range frequencies {
+		lowercase := unicode.ToLower(letter)
+		if _, ok := table.frequencies[lowercase]; ok {
+			return nil, &ErrInvalidFrequencyTable{fmt.Sprintf("duplicate letter: %q", letter)}
+		}
+		table.frequencies[lowercase] = frequency / total
+		table.letters = append(table.letters, lowercase)
+	}
+	slices.SortFunc(table.letters, func(a, b rune) int {
+		if order := cmp.Compare(table.frequencies[b], table.frequencies[a]); order != 0 {
+			return order
+		}
+		return cmp.Compare(a, b)
+	})
+	return table, nil
+}
+
+func mustNewFrequencyTable(frequencies map[rune]float64) *FrequencyTable {
+	table, err := NewFrequencyTable(frequencies)
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+// ParseFrequencyTable reads the table from the lines consisting of a letter and its frequency, separated
+// with whitespace. The empty lines and the lines starting with # are skipped.
+func ParseFrequencyTable(reader io.Reader) (*FrequencyTable, error) {
+	frequencies := make(map[rune]float64)
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 {
+			return nil, &ErrInvalidFrequencyTable{fmt.Sprintf("expected a letter and its frequency at line: %d", lineNumber)}
+		}
+		frequency, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, &ErrInvalidFrequencyTable{fmt.Sprintf("invalid frequency: %s at line: %d", fields[1], lineNumber)}
+		}
+		letter, _ := utf8.DecodeRuneInString(fields[0])
+		frequencies[letter] = frequency
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewFrequencyTable(frequencies)
+}
+
+// Letters returns the letters of the table, from the most to the least frequent one.
+func (table *FrequencyTable) Letters() []rune {
+	return slices.Clone(table.letters)
+}
+
+func (table *FrequencyTable) Frequency(letter rune) float64 {
+	return table.frequencies[unicode.ToLower(letter)]
+}
+
+// foreignShare is the expected share of the runes, which are neither letters of the table nor spaces,
+// digits or punctuation, in a text of the language.
+const foreignShare = 0.01
+
+// ChiSquared scores how far the text is from the language, the lower the closer. Besides the letters of
+// the table, the runes foreign to the language are counted as one more category, so that the texts of
+// random runes score badly even though they contain few letters. The spaces, the digits and the
+// punctuation are neutral.
+func (table *FrequencyTable) ChiSquared(text []rune) float64 {
+	counts := make(map[rune]int, len(table.frequencies))
+	total, foreign := 0, 0
+	for _, r := range text {
+		lowercase := unicode.ToLower(r)
+		switch {
+		case table.frequencies[lowercase] != 0:
+			counts[lowercase]++
+			total++
+		case unicode.IsSpace(r), unicode.IsDigit(r), unicode.IsPunct(r):
+		default:
+			foreign++
+			total++
+		}
+	}
+	if total == 0 {
+		return math.Inf(1)
+	}
+	chiSquared := squaredDeviation(foreign, float64(total)*foreignShare)
+	for letter, frequency := range table.frequencies {
+		chiSquared += squaredDeviation(counts[letter], float64(total)*(1-foreignShare)*frequency)
+	}
+	return chiSquared
+}
+
+func squaredDeviation(observed int, expected float64) float64 {
+	deviation := float64(observed) - expected
+	return deviation * deviation / expected
+}
+
+// Letter frequencies in percents, after R. Lewand, Cryptological Mathematics, for English, and after
+// the frequency lists of the Polish Wikipedia, for Polish.
+var (
+	English = mustNewFrequencyTable(map[rune]float64{
+		'a': 8.167, 'b': 1.492, 'c': 2.782, 'd': 4.253, 'e': 12.702, 'f': 2.228, 'g': 2.015, 'h': 6.094,
+		'i': 6.966, 'j': 0.153, 'k': 0.772, 'l': 4.025, 'm': 2.406, 'n': 6.749, 'o': 7.507, 'p': 1.929,
+		'q': 0.095, 'r': 5.987, 's': 6.327, 't': 9.056, 'u': 2.758, 'v': 0.978, 'w': 2.360, 'x': 0.150,
+		'y': 1.974, 'z': 0.074,
+	})
+	Polish = mustNewFrequencyTable(map[rune]float64{
+		'a': 8.91, 'ą': 0.99, 'b': 1.47, 'c': 3.96, 'ć': 0.40, 'd': 3.25, 'e': 7.66, 'ę': 1.11,
+		'f': 0.30, 'g': 1.42, 'h': 1.08, 'i': 8.21, 'j': 2.28, 'k': 3.51, 'l': 2.10, 'ł': 1.82,
+		'm': 2.80, 'n': 5.52, 'ń': 0.20, 'o': 7.75, 'ó': 0.85, 'p': 3.13, 'r': 4.69, 's': 4.32,
+		'ś': 0.66, 't': 3.98, 'u': 2.50, 'w': 4.65, 'y': 3.76, 'z': 5.64, 'ź': 0.06, 'ż': 0.83,
+	})
+)
+
+const (
+	EnglishName = "english"
+	PolishName  = "polish"
+)
+
+var namedFrequencyTables = map[string]*FrequencyTable{
+	EnglishName: English,
+	"en":        English,
+	PolishName:  Polish,
+	"pl":        Polish,
+}
+
+// GetFrequencyTable returns the built-in table of the language given by its name or by its ISO 639-1 code.
+func GetFrequencyTable(language string) (*FrequencyTable, bool) {
+	table, ok := namedFrequencyTables[strings.ToLower(language)]
+	return table, ok
+}
+
+type ErrInvalidFrequencyTable struct {
+	Reason string
+}
+
+func (e *ErrInvalidFrequencyTable) Error() string {
+	return "invalid frequency table: " + e.Reason
+}
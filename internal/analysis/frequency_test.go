@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readTestCorpus(t *testing.T, name string) []rune {
+	corpus, err := os.ReadFile("testdata/" + name)
+	assert.NoError(t, err)
+	return []rune(string(corpus))
+}
+
+func Test_NewFrequencyTable_normalizes(t *testing.T) {
+	// given
+	frequencies := map[rune]float64{'A': 3, 'b': 1}
+	// when
+	table, err := NewFrequencyTable(frequencies)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, table.Frequency('a'))
+	assert.Equal(t, 0.25, table.Frequency('B'))
+	assert.Equal(t, []rune{'a', 'b'}, table.Letters())
+}
+
+func Test_NewFrequencyTable_invalid(t *testing.T) {
+	// given
+	inputs := []map[rune]float64{
+		{},
+		{'a': 1, '1': 1},
+		{'a': -1},
+		{'a': math.NaN()},
+		{'a': 1, 'A': 1},
+	}
+	for _, input := range inputs {
+		// when
+		table, err := NewFrequencyTable(input)
+		// then
+		assert.Nil(t, table)
+		assert.IsType(t, &ErrInvalidFrequencyTable{}, err, input)
+	}
+}
+
+func Test_ParseFrequencyTable(t *testing.T) {
+	// given
+	input := "# Hawaiian vowels\n\na 40\ne 15\ni 15\n o 15 \nu 15\n"
+	// when
+	table, err := ParseFrequencyTable(strings.NewReader(input))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []rune{'a', 'e', 'i', 'o', 'u'}, table.Letters())
+	assert.Equal(t, 0.4, table.Frequency('a'))
+}
+
+func Test_ParseFrequencyTable_invalid(t *testing.T) {
+	// given
+	inputs := []string{"a", "ab 1", "a one", "a 1 2"}
+	for _, input := range inputs {
+		// when
+		_, err := ParseFrequencyTable(strings.NewReader(input))
+		// then
+		assert.IsType(t, &ErrInvalidFrequencyTable{}, err, input)
+	}
+}
+
+func Test_ChiSquared_prefersTheLanguage(t *testing.T) {
+	// given
+	english := readTestCorpus(t, "english.txt")
+	polish := readTestCorpus(t, "polish.txt")
+	// then
+	assert.Less(t, English.ChiSquared(english), English.ChiSquared(polish))
+	assert.Less(t, Polish.ChiSquared(polish), Polish.ChiSquared(english))
+	assert.True(t, math.IsInf(English.ChiSquared([]rune("123 !?")), 1))
+}
+
+func Test_GetFrequencyTable(t *testing.T) {
+	// given
+	languages := []string{"english", "EN", "polish", "pl"}
+	expectedTables := []*FrequencyTable{English, English, Polish, Polish}
+	for i, language := range languages {
+		// when
+		table, ok := GetFrequencyTable(language)
+		// then
+		assert.True(t, ok, language)
+		assert.Same(t, expectedTables[i], table, language)
+	}
+}
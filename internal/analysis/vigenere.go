@@ -0,0 +1,205 @@
+package analysis
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"unicode"
+
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+)
+
+// The shortest of the key lengths scoring within the keyLengthTolerance of the best one is chosen, as the
+// multiples of the key length score almost as well as the key length itself.
+const (
+	keyLengthTolerance = 0.05
+	kasiskiSequence    = 3
+)
+
+// KeyLength is a key length scored by the closeness of the average index of coincidence of its key
+// columns to the one of the language, and by the excess share, over the share expected by chance, of the
+// Kasiski distances it divides. Both of them and so the Score lie within 0-1.
+type KeyLength struct {
+	Length             int
+	IndexOfCoincidence float64
+	Kasiski            float64
+	Score              float64
+}
+
+// VigenereSolution is the key recovered along with the ranked key lengths it has been chosen from.
+type VigenereSolution struct {
+	Key        string
+	KeyLengths []KeyLength
+	Plaintext  []rune
+}
+
+// CrackVigenere recovers the key of the Vigenère cipher of algorithms.NewVigenereRuneFunc, which shifts the
+// Latin letters only, but advances the key on every rune. Every column of the key is recovered like the
+// key of the Caesar cipher within the Latin alphabets.
+func CrackVigenere(ciphertext []rune, table *FrequencyTable, maxKeyLength int) (*VigenereSolution, error) {
+	keyLengths, err := EstimateKeyLengths(ciphertext, table, maxKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	keyLength := chooseKeyLength(keyLengths)
+	latinAlphabets, _ := algorithms.GetAlphabets(algorithms.LatinAlphabetsName)
+	shifts := make([]int, algorithms.LatinLowercase.Size())
+	for i := range shifts {
+		shifts[i] = i
+	}
+	key := make([]rune, keyLength)
+	for column := range key {
+		candidates := rankCandidates(keyColumn(ciphertext, keyLength, column), table, shifts, func(shift int) func(rune) rune {
+			return algorithms.NewAlphabetOffsetRuneFunc(shift, false, latinAlphabets...)
+		})
+		key[column] = 'a' + rune(candidates[0].Key)
+	}
+	decodeFunc, err := algorithms.NewVigenereRuneFunc(string(key), false)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]rune, len(ciphertext))
+	for i, r := range ciphertext {
+		plaintext[i] = decodeFunc(i, r)
+	}
+	return &VigenereSolution{string(key), keyLengths, plaintext}, nil
+}
+
+// EstimateKeyLengths ranks the key lengths from 1 to the maxKeyLength, from the most to the least likely one.
+func EstimateKeyLengths(ciphertext []rune, table *FrequencyTable, maxKeyLength int) ([]KeyLength, error) {
+	letterCount := 0
+	for _, r := range ciphertext {
+		if isLatinLetter(r) {
+			letterCount++
+		}
+	}
+	if letterCount < 2 {
+		return nil, ErrNotEnoughLetters
+	}
+	languageIndex := table.latinIndexOfCoincidence()
+	distances := kasiskiDistances(ciphertext)
+	keyLengths := make([]KeyLength, 0, maxKeyLength)
+	for length := 1; length <= maxKeyLength; length++ {
+		index := averageIndexOfCoincidence(ciphertext, length)
+		closeness := max(0, 1-math.Abs(index-languageIndex)/languageIndex)
+		kasiski := kasiskiExcess(distances, length)
+		keyLengths = append(keyLengths, KeyLength{length, index, kasiski, (closeness + kasiski) / 2})
+	}
+	slices.SortStableFunc(keyLengths, func(a, b KeyLength) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return keyLengths, nil
+}
+
+func chooseKeyLength(keyLengths []KeyLength) int {
+	best := keyLengths[0]
+	for _, keyLength := range keyLengths {
+		if keyLength.Score >= best.Score*(1-keyLengthTolerance) && keyLength.Length < best.Length {
+			best = keyLength
+		}
+	}
+	return best.Length
+}
+
+// keyColumn returns the Latin letters at the positions shifted by the given letter of the key.
+func keyColumn(ciphertext []rune, keyLength int, column int) []rune {
+	letters := make([]rune, 0, len(ciphertext)/keyLength+1)
+	for i := column; i < len(ciphertext); i += keyLength {
+		if isLatinLetter(ciphertext[i]) {
+			letters = append(letters, ciphertext[i])
+		}
+	}
+	return letters
+}
+
+// averageIndexOfCoincidence skips the columns with fewer than two letters.
+func averageIndexOfCoincidence(ciphertext []rune, keyLength int) float64 {
+	total, columns := 0.0, 0
+	for column := 0; column < keyLength; column++ {
+		letters := keyColumn(ciphertext, keyLength, column)
+		if len(letters) < 2 {
+			continue
+		}
+		total += indexOfCoincidence(letters)
+		columns++
+	}
+	if columns == 0 {
+		return 0
+	}
+	return total / float64(columns)
+}
+
+// indexOfCoincidence is the probability that two letters drawn from the text without replacement are the same.
+func indexOfCoincidence(letters []rune) float64 {
+	counts := make(map[rune]int)
+	for _, letter := range letters {
+		counts[unicode.ToLower(letter)]++
+	}
+	coincidences := 0
+	for _, count := range counts {
+		coincidences += count * (count - 1)
+	}
+	return float64(coincidences) / float64(len(letters)*(len(letters)-1))
+}
+
+// latinIndexOfCoincidence is the index of coincidence of the Latin letters of the language.
+func (table *FrequencyTable) latinIndexOfCoincidence() float64 {
+	total, squares := 0.0, 0.0
+	for letter, frequency := range table.frequencies {
+		if isLatinLetter(letter) {
+			total += frequency
+			squares += frequency * frequency
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return squares / (total * total)
+}
+
+// kasiskiDistances returns the distances between the consecutive occurrences of the same sequences of
+// letters, which are multiples of the key length when the same plaintext has been shifted the same way.
+func kasiskiDistances(ciphertext []rune) []int {
+	lastPositions := make(map[[kasiskiSequence]rune]int)
+	var distances []int
+	for i := 0; i+kasiskiSequence <= len(ciphertext); i++ {
+		var sequence [kasiskiSequence]rune
+		isLetterSequence := true
+		for j := range sequence {
+			sequence[j] = unicode.ToLower(ciphertext[i+j])
+			isLetterSequence = isLetterSequence && isLatinLetter(sequence[j])
+		}
+		if !isLetterSequence {
+			continue
+		}
+		if lastPosition, ok := lastPositions[sequence]; ok {
+			distances = append(distances, i-lastPosition)
+		}
+		lastPositions[sequence] = i
+	}
+	return distances
+}
+
+// kasiskiExcess compares the share of the distances divisible by the key length with the share 1/length
+// expected by chance, so that the short lengths, which divide many distances anyway, are not favoured.
+func kasiskiExcess(distances []int, length int) float64 {
+	if len(distances) == 0 || length == 1 {
+		return 0
+	}
+	divisible := 0
+	for _, distance := range distances {
+		if distance%length == 0 {
+			divisible++
+		}
+	}
+	share := float64(divisible) / float64(len(distances))
+	chance := 1 / float64(length)
+	return max(0, (share-chance)/(1-chance))
+}
+
+func isLatinLetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+var ErrNotEnoughLetters = errors.New("input has too few Latin letters to analyse")
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func encodeVigenereTestCorpus(t *testing.T, corpus []rune, key string) []rune {
+	encodeFunc, err := algorithms.NewVigenereRuneFunc(key, true)
+	assert.NoError(t, err)
+	encoded := make([]rune, len(corpus))
+	for i, r := range corpus {
+		encoded[i] = encodeFunc(i, r)
+	}
+	return encoded
+}
+
+func Test_CrackVigenere(t *testing.T) {
+	// given
+	corpora := []string{"vigenere/english.txt", "vigenere/english.txt", "vigenere/english.txt", "vigenere/polish.txt", "vigenere/polish.txt"}
+	tables := []*FrequencyTable{English, English, English, Polish, Polish}
+	keys := []string{"lemon", "key", "cryptanalysis", "ojczyzna", "mickiewicz"}
+	for i, corpus := range corpora {
+		plaintext := readTestCorpus(t, corpus)
+		ciphertext := encodeVigenereTestCorpus(t, plaintext, keys[i])
+		// when
+		solution, err := CrackVigenere(ciphertext, tables[i], 20)
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, keys[i], solution.Key, corpus)
+		assert.Equal(t, string(plaintext), string(solution.Plaintext), corpus)
+		assert.Len(t, solution.KeyLengths, 20)
+	}
+}
+
+func Test_EstimateKeyLengths(t *testing.T) {
+	// given
+	ciphertext := encodeVigenereTestCorpus(t, readTestCorpus(t, "vigenere/english.txt"), "lemon")
+	// when
+	keyLengths, err := EstimateKeyLengths(ciphertext, English, 12)
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, keyLengths, 12)
+	assert.Contains(t, []int{5, 10}, keyLengths[0].Length)
+	assert.Greater(t, keyLengths[0].Kasiski, 0.5)
+	for i := 1; i < len(keyLengths); i++ {
+		assert.GreaterOrEqual(t, keyLengths[i-1].Score, keyLengths[i].Score)
+	}
+}
+
+func Test_EstimateKeyLengths_notEnoughLetters(t *testing.T) {
+	// given
+	ciphertext := []rune("1, 2: ż!")
+	// when
+	_, err := EstimateKeyLengths(ciphertext, English, 20)
+	// then
+	assert.ErrorIs(t, err, ErrNotEnoughLetters)
+}
+
+func Test_indexOfCoincidence(t *testing.T) {
+	// given
+	letters := []rune("aAbc")
+	// when
+	index := indexOfCoincidence(letters)
+	// then
+	assert.InDelta(t, 1.0/6, index, 1e-9)
+}
@@ -1,11 +1,16 @@
 package ciphers
 
 import (
-	"bytes"
+	"bufio"
+	"errors"
+	"io"
+	"maps"
+	"runtime"
+	"strings"
 
-	"github.com/mat-sik/encoder-decoder/internal/algorithms"
 	"github.com/mat-sik/encoder-decoder/internal/parser"
 	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
 )
 
 type CipherRunner interface {
@@ -13,122 +18,277 @@ type CipherRunner interface {
 }
 
 type BasicCipherRunner struct {
-	cipher Cipher
-	mode   parser.Mode
+	cipher      *cipher.Cipher
+	cipherInput *CipherInput
+	mode        parser.Mode
 }
 
 func (cipherRunner *BasicCipherRunner) Run() error {
-	cipher := cipherRunner.cipher
 	switch cipherRunner.mode {
 	case parser.Encode:
-		return cipher.encode()
+		return cipherRunner.cipherInput.transfer(cipherRunner.cipher.Encode)
 	case parser.Decode:
-		return cipher.decode()
+		return cipherRunner.cipherInput.transfer(cipherRunner.cipher.Decode)
 	default:
-		panic("technically this is not possible")
+		return &parser.ErrUnknownMode{Mode: string(cipherRunner.mode)}
 	}
 }
 
-type Cipher interface {
-	encode() error
-	decode() error
-}
-
+// NewCipherRunner takes the mode from the argMap, see NewModeCipherRunner.
 func NewCipherRunner(argMap map[string]string) (CipherRunner, error) {
-	alg, err := parser.GetAlgValue(argMap)
+	mode, err := parser.GetModeValue(argMap)
 	if err != nil {
 		return nil, err
 	}
-	mode, err := parser.GetModeValue(argMap)
+	return NewModeCipherRunner(argMap, mode)
+}
+
+func NewModeCipherRunner(argMap map[string]string, mode parser.Mode) (CipherRunner, error) {
+	if mode == parser.Decode {
+		return newDecodingCipherRunner(argMap)
+	}
+	algCipher, header, cipherInput, err := newCipher(argMap)
 	if err != nil {
 		return nil, err
 	}
-	var cipher Cipher
-	switch alg {
-	case parser.Caesar:
-		cipher, err = newCaesarCipherInput(argMap)
-	case parser.Mirror:
-		cipher, err = newMirrorCipherInput(argMap)
-	default:
-		panic("technically this is not possible")
+	if parser.IsContainerRequested(argMap) {
+		return &ContainerCipherRunner{algCipher, header, cipherInput}, nil
 	}
-	return &BasicCipherRunner{cipher, mode}, err
+	return &BasicCipherRunner{algCipher, cipherInput, mode}, nil
 }
 
-type CipherInput struct {
-	InPath  string
-	OutPath string
+// ContainerCipherRunner encodes the input into a container described by the header.
+type ContainerCipherRunner struct {
+	cipher      *cipher.Cipher
+	header      cipher.Header
+	cipherInput *CipherInput
 }
 
-func newCipherInput(argMap map[string]string) (*CipherInput, error) {
-	in, err := parser.GetInValue(argMap)
-	if err != nil {
-		return nil, err
+func (cipherRunner *ContainerCipherRunner) Run() error {
+	return cipherRunner.cipherInput.transfer(func(reader io.Reader, writer io.Writer) error {
+		return cipherRunner.cipher.EncodeContainer(reader, writer, cipherRunner.header)
+	})
+}
+
+// DecodingCipherRunner decodes the container detected at the start of the input, or the plain input
+// otherwise. Without the algorithm flag, the algorithms and the unit are taken from the container header.
+type DecodingCipherRunner struct {
+	cipher      *cipher.Cipher
+	flagParams  map[string]string
+	options     cipherOptions
+	cipherInput *CipherInput
+}
+
+func newDecodingCipherRunner(argMap map[string]string) (CipherRunner, error) {
+	var errMissingFlag *parser.ErrMissingFlag
+	if _, err := parser.GetPipelineValue(argMap); errors.As(err, &errMissingFlag) {
+		cipherInput, err := newCipherInput(argMap)
+		if err != nil {
+			return nil, err
+		}
+		options, err := newCipherOptions(argMap)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodingCipherRunner{nil, parser.GetParamValues(argMap), options, cipherInput}, nil
 	}
-	out, err := parser.GetOutValue(argMap)
+	algCipher, _, cipherInput, err := newCipher(argMap)
 	if err != nil {
 		return nil, err
 	}
-	return &CipherInput{in, out}, nil
+	return &DecodingCipherRunner{algCipher, nil, cipherOptions{}, cipherInput}, nil
 }
 
-type CaesarCipherInput struct {
-	CipherInput     *CipherInput
-	CaesarCipherKey int
+func (cipherRunner *DecodingCipherRunner) Run() error {
+	return cipherRunner.cipherInput.transfer(func(reader io.Reader, writer io.Writer) error {
+		bufferedReader := bufio.NewReaderSize(reader, transformer.ReadBufferSize)
+		header, err := cipher.ReadHeader(bufferedReader)
+		switch {
+		case errors.Is(err, cipher.ErrNotContainer) && cipherRunner.cipher == nil:
+			return &parser.ErrMissingFlag{RequiredFlag: parser.ChosenAlg, RequiredFlagFull: parser.ChosenAlgFull}
+		case errors.Is(err, cipher.ErrNotContainer):
+			return cipherRunner.cipher.Decode(bufferedReader, writer)
+		case err != nil:
+			return err
+		}
+		algCipher := cipherRunner.cipher
+		if algCipher == nil {
+			if algCipher, err = newHeaderCipher(header, cipherRunner.flagParams); err != nil {
+				return err
+			}
+			algCipher = cipherRunner.options.apply(algCipher)
+		}
+		return algCipher.DecodeContainer(bufferedReader, writer)
+	})
 }
 
-func newCaesarCipherInput(argMap map[string]string) (*CaesarCipherInput, error) {
+// NewCipher creates the pipeline of the registered algorithms chosen in the argMap, along with its input.
+func NewCipher(argMap map[string]string) (*cipher.Cipher, *CipherInput, error) {
+	algCipher, _, cipherInput, err := newCipher(argMap)
+	return algCipher, cipherInput, err
+}
+
+func newCipher(argMap map[string]string) (*cipher.Cipher, cipher.Header, *CipherInput, error) {
+	stages, err := parser.GetPipelineValue(argMap)
+	if err != nil {
+		return nil, cipher.Header{}, nil, err
+	}
 	cipherInput, err := newCipherInput(argMap)
 	if err != nil {
-		return nil, err
+		return nil, cipher.Header{}, nil, err
 	}
-	key, err := parser.GetIntKeyValue(argMap)
+	algCipher, header, err := newPipeline(stages, cipher.Unit(cipherInput.Unit), parser.GetParamValues(argMap))
 	if err != nil {
-		return nil, err
+		return nil, cipher.Header{}, nil, err
+	}
+	options, err := newCipherOptions(argMap)
+	if err != nil {
+		return nil, cipher.Header{}, nil, err
+	}
+	return options.apply(algCipher), header, cipherInput, nil
+}
+
+// cipherOptions are the flags tuning how the input is transferred, which do not change its encoding.
+type cipherOptions struct {
+	bufferSize    int
+	invalidPolicy transformer.InvalidPolicy
+	workers       int
+}
+
+func newCipherOptions(argMap map[string]string) (cipherOptions, error) {
+	bufferSize, err := parser.GetBufferSizeValue(argMap)
+	if err != nil {
+		return cipherOptions{}, err
+	}
+	invalidPolicy, err := parser.GetInvalidPolicyValue(argMap)
+	if err != nil {
+		return cipherOptions{}, err
 	}
-	return &CaesarCipherInput{cipherInput, key}, nil
+	return cipherOptions{bufferSize, invalidPolicy, getWorkers(argMap)}, nil
 }
 
-func (input *CaesarCipherInput) encode() error {
-	var key = int32(input.CaesarCipherKey)
-	encodeFunc := algorithms.NewOffsetRuneFunc(key)
-	return input.CipherInput.transform(encodeFunc)
+func (options cipherOptions) apply(algCipher *cipher.Cipher) *cipher.Cipher {
+	return algCipher.WithBufferSize(options.bufferSize).WithInvalidPolicy(options.invalidPolicy).WithWorkers(options.workers)
 }
 
-func (input *CaesarCipherInput) decode() error {
-	var key = -int32(input.CaesarCipherKey)
-	decodeFunc := algorithms.NewOffsetRuneFunc(key)
-	return input.CipherInput.transform(decodeFunc)
+// getWorkers returns the number of workers transforming the input, zero standing for the sequential transfer.
+func getWorkers(argMap map[string]string) int {
+	if parser.IsParallelRequested(argMap) {
+		return runtime.GOMAXPROCS(0)
+	}
+	return 0
 }
 
-type MirrorCipherInput struct {
-	CipherInput *CipherInput
+// newHeaderCipher creates the pipeline described by the container header, the parameter flags supplying
+// the secret parameters left out of it.
+func newHeaderCipher(header cipher.Header, flagParams map[string]string) (*cipher.Cipher, error) {
+	stages := make([]parser.Stage, 0, len(header.Algorithms))
+	for _, headerAlgorithm := range header.Algorithms {
+		algorithm, ok := cipher.Lookup(headerAlgorithm.Name)
+		if !ok {
+			return nil, &parser.ErrUnknownAlgorithm{Alg: headerAlgorithm.Name}
+		}
+		stages = append(stages, parser.Stage{Alg: parser.Alg(algorithm.Name), Params: headerAlgorithm.Params})
+	}
+	algCipher, _, err := newPipeline(stages, header.Unit, flagParams)
+	return algCipher, err
 }
 
-func newMirrorCipherInput(argMap map[string]string) (*MirrorCipherInput, error) {
-	cipherInput, err := newCipherInput(argMap)
+// newPipeline creates the pipeline of the stages along with the container header describing it.
+func newPipeline(stages []parser.Stage, unit cipher.Unit, flagParams map[string]string) (*cipher.Cipher, cipher.Header, error) {
+	stageParams, err := distributeParams(stages, flagParams)
 	if err != nil {
-		return nil, err
+		return nil, cipher.Header{}, err
+	}
+	stageCiphers := make([]*cipher.Cipher, 0, len(stages))
+	header := cipher.Header{Unit: unit, Algorithms: make([]cipher.HeaderAlgorithm, 0, len(stages))}
+	for i, stage := range stages {
+		algorithm, _ := cipher.Lookup(string(stage.Alg))
+		stageCipher, err := algorithm.NewCipher(unit, stageParams[i])
+		if err != nil {
+			return nil, cipher.Header{}, err
+		}
+		stageCiphers = append(stageCiphers, stageCipher)
+		header.Algorithms = append(header.Algorithms, newHeaderAlgorithm(algorithm, stageParams[i]))
+	}
+	pipeline, err := cipher.NewPipeline(stageCiphers...)
+	if err != nil {
+		return nil, cipher.Header{}, err
 	}
-	return &MirrorCipherInput{cipherInput}, nil
+	return pipeline, header, nil
 }
 
-func (input *MirrorCipherInput) encode() error {
-	encodeFunc := algorithms.GetMirrorRuneLatin1
-	return input.CipherInput.transform(encodeFunc)
+func newHeaderAlgorithm(algorithm cipher.Algorithm, params map[string]string) cipher.HeaderAlgorithm {
+	headerAlgorithm := cipher.HeaderAlgorithm{Name: algorithm.Name}
+	for _, param := range algorithm.Params {
+		value, ok := params[param.Name]
+		if !ok || param.Secret {
+			continue
+		}
+		if headerAlgorithm.Params == nil {
+			headerAlgorithm.Params = make(map[string]string)
+		}
+		headerAlgorithm.Params[param.Name] = value
+	}
+	return headerAlgorithm
 }
 
-func (input *MirrorCipherInput) decode() error {
-	decodeFunc := algorithms.GetMirrorRuneLatin1
-	return input.CipherInput.transform(decodeFunc)
+// distributeParams passes the parameter flags to every stage taking them, unless the stage sets them inline.
+// A parameter taken by none of the stages is reported as unsupported by the whole pipeline.
+func distributeParams(stages []parser.Stage, flagParams map[string]string) ([]map[string]string, error) {
+	stageParams := make([]map[string]string, 0, len(stages))
+	takenParams := make(map[string]bool)
+	for _, stage := range stages {
+		algorithm, _ := cipher.Lookup(string(stage.Alg))
+		params := make(map[string]string)
+		for _, param := range algorithm.Params {
+			if value, ok := flagParams[param.Name]; ok {
+				params[param.Name] = value
+				takenParams[param.Name] = true
+			}
+		}
+		maps.Copy(params, stage.Params)
+		stageParams = append(stageParams, params)
+	}
+	for name := range flagParams {
+		if !takenParams[name] {
+			return nil, &cipher.ErrUnsupportedParam{Alg: pipelineName(stages), Param: name}
+		}
+	}
+	return stageParams, nil
 }
 
-func (input *CipherInput) transform(transformFunc func(rune) rune) error {
-	inPath := input.InPath
-	outPath := input.OutPath
+func pipelineName(stages []parser.Stage) string {
+	algNames := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		algNames = append(algNames, string(stage.Alg))
+	}
+	return strings.Join(algNames, parser.StageSeparator)
+}
+
+// CipherInput paths equal to transformer.StdStreamPath stand for the standard input and output.
+type CipherInput struct {
+	InPath  string
+	OutPath string
+	Unit    parser.Unit
+}
 
-	inBuffer := bytes.NewBuffer(make([]byte, 0, transformer.ReadBufferSize))
-	outBuffer := bytes.NewBuffer(make([]byte, 0, transformer.WriteBufferSize))
+func newCipherInput(argMap map[string]string) (*CipherInput, error) {
+	in, ok := parser.GetInValue(argMap)
+	if !ok {
+		in = transformer.StdStreamPath
+	}
+	out, ok := parser.GetOutValue(argMap)
+	if !ok {
+		out = transformer.StdStreamPath
+	}
+	unit, err := parser.GetUnitValue(argMap)
+	if err != nil {
+		return nil, err
+	}
+	return &CipherInput{in, out, unit}, nil
+}
 
-	return transformer.FilesApplyFuncAndTransfer(inPath, outPath, inBuffer, outBuffer, transformFunc)
+func (input *CipherInput) transfer(transfer func(io.Reader, io.Writer) error) error {
+	return transformer.FilesTransfer(input.InPath, input.OutPath, transfer)
 }
@@ -2,7 +2,12 @@ package ciphers
 
 import (
 	"github.com/mat-sik/encoder-decoder/internal/parser"
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -13,22 +18,170 @@ func Test_newCipher(t *testing.T) {
 		"-i": "foo.txt",
 		"-o": "bar.txt",
 		"-a": "mirror",
-		"-k": "123",
 	}
-	expectedInput := &BasicCipherRunner{
-		cipher: &MirrorCipherInput{
-			CipherInput: &CipherInput{
-				InPath:  "foo.txt",
-				OutPath: "bar.txt",
-			},
-		},
-		mode: parser.Encode,
+	expectedCipherInput := &CipherInput{
+		InPath:  "foo.txt",
+		OutPath: "bar.txt",
+		Unit:    parser.Rune,
 	}
+	expectedMode := parser.Encode
 	var expectedErr error = nil
 	// when
 	resultCipher, resultErr := NewCipherRunner(argMap)
 	// then
 	assert.Equal(t, expectedErr, resultErr)
-	assert.IsType(t, expectedInput, resultCipher)
-	assert.Equal(t, expectedInput, resultCipher)
+	assert.IsType(t, &BasicCipherRunner{}, resultCipher)
+	assert.Equal(t, expectedCipherInput, resultCipher.(*BasicCipherRunner).cipherInput)
+	assert.Equal(t, expectedMode, resultCipher.(*BasicCipherRunner).mode)
+}
+
+func Test_newCipher_stdStreams(t *testing.T) {
+	// given
+	argMap := map[string]string{
+		"-o": "-",
+		"-a": "mirror",
+	}
+	expectedCipherInput := &CipherInput{
+		InPath:  transformer.StdStreamPath,
+		OutPath: transformer.StdStreamPath,
+		Unit:    parser.Rune,
+	}
+	var expectedErr error = nil
+	// when
+	_, resultCipherInput, resultErr := NewCipher(argMap)
+	// then
+	assert.Equal(t, expectedErr, resultErr)
+	assert.Equal(t, expectedCipherInput, resultCipherInput)
+}
+
+func Test_newCipher_invalidParams(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{"-a": "xor", "-k": "90"},
+		{"-a": "vigenere", "-k": "key", "-u": "byte"},
+		{"-a": "caesar", "-k": "3", "-u": "byte", "-l": "latin"},
+		{"-a": "caesar"},
+		{"-a": "caesar", "-k": "three"},
+		{"-a": "xor", "-k": "256", "-u": "byte"},
+		{"-a": "mirror", "-k": "3"},
+	}
+	expectedErrs := []error{
+		&cipher.ErrUnsupportedUnit{Alg: "xor", Unit: cipher.RuneUnit},
+		&cipher.ErrUnsupportedUnit{Alg: "vigenere", Unit: cipher.ByteUnit},
+		&cipher.ErrUnsupportedParam{Alg: "caesar", Param: cipher.AlphabetParam},
+		&cipher.ErrMissingParam{Alg: "caesar", Param: cipher.KeyParam},
+		&cipher.ErrInvalidParam{Alg: "caesar", Param: cipher.KeyParam, Value: "three", Reason: "expected an integer"},
+		&cipher.ErrInvalidParam{Alg: "xor", Param: cipher.KeyParam, Value: "256", Reason: "expected an integer within 0-255"},
+		&cipher.ErrUnsupportedParam{Alg: "mirror", Param: cipher.KeyParam},
+	}
+	for i, argMap := range argMaps {
+		// when
+		_, _, resultErr := NewCipher(argMap)
+		// then
+		assert.Equal(t, expectedErrs[i], resultErr, argMap)
+	}
+}
+
+func Test_BasicCipherRunner_Run(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.txt")
+	encodedPath := filepath.Join(dir, "encoded.txt")
+	decodedPath := filepath.Join(dir, "decoded.txt")
+	if err := os.WriteFile(inPath, []byte("Attack at dawn!"), 0644); err != nil {
+		panic(err)
+	}
+	encodeArgMap := map[string]string{"-m": "encode", "-a": "vigenère", "-k": "lemon", "-i": inPath, "-o": encodedPath}
+	decodeArgMap := map[string]string{"-m": "decode", "-a": "vigenere", "-k": "lemon", "-i": encodedPath, "-o": decodedPath}
+	// when
+	encodeRunner, encodeErr := NewCipherRunner(encodeArgMap)
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, encodeRunner.Run())
+	decodeRunner, decodeErr := NewCipherRunner(decodeArgMap)
+	assert.NoError(t, decodeErr)
+	assert.NoError(t, decodeRunner.Run())
+	// then
+	encoded, _ := os.ReadFile(encodedPath)
+	decoded, _ := os.ReadFile(decodedPath)
+	assert.Equal(t, "Lxfopv mh oeib!", string(encoded))
+	assert.Equal(t, "Attack at dawn!", string(decoded))
+}
+
+func Test_BasicCipherRunner_Run_unknownMode(t *testing.T) {
+	// given
+	cipherRunner := &BasicCipherRunner{cipher.NewMirror(), &CipherInput{}, parser.Mode("crack")}
+	expectedErr := &parser.ErrUnknownMode{Mode: "crack"}
+	// when
+	resultErr := cipherRunner.Run()
+	// then
+	assert.Equal(t, expectedErr, resultErr)
+}
+
+func Test_newCipher_pipeline(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{"-a": "caesar:1,vigenere", "-k": "ab"},
+		{"-a": "caesar,caesar:2", "-k": "1"},
+		{"-a": "caesar:1,mirror", "-l": "latin"},
+		{"-a": "mirror,mirror", "-k": "1"},
+	}
+	expectedEncoded := []string{"bdd", "def", "yxw"}
+	expectedErrs := []error{
+		nil,
+		nil,
+		nil,
+		&cipher.ErrUnsupportedParam{Alg: "mirror,mirror", Param: cipher.KeyParam},
+	}
+	for i, argMap := range argMaps {
+		// when
+		resultCipher, _, resultErr := NewCipher(argMap)
+		// then
+		assert.Equal(t, expectedErrs[i], resultErr, argMap)
+		if resultErr != nil {
+			continue
+		}
+		encoded := new(strings.Builder)
+		assert.NoError(t, resultCipher.Encode(strings.NewReader("abc"), encoded))
+		assert.Equal(t, expectedEncoded[i], encoded.String(), argMap)
+	}
+}
+
+func Test_ContainerCipherRunner_Run(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.txt")
+	containerPath := filepath.Join(dir, "container.bin")
+	decodedPath := filepath.Join(dir, "decoded.txt")
+	if err := os.WriteFile(inPath, []byte("Attack at dawn!"), 0644); err != nil {
+		panic(err)
+	}
+	encodeArgMap := map[string]string{"-a": "vigenere,mirror", "-k": "lemon", "-c": "", "-i": inPath, "-o": containerPath}
+	decodeArgMaps := []map[string]string{
+		{"-k": "lemon", "-i": containerPath, "-o": decodedPath},
+		{"-a": "vigenere,mirror", "-k": "lemon", "-i": containerPath, "-o": decodedPath},
+		{"-k": "lemons", "-i": containerPath, "-o": decodedPath},
+		{"-k": "lemon", "-i": inPath, "-o": decodedPath},
+	}
+	expectedErrs := []error{
+		nil,
+		nil,
+		cipher.ErrWrongKeyOrCorrupted,
+		&parser.ErrMissingFlag{RequiredFlag: parser.ChosenAlg, RequiredFlagFull: parser.ChosenAlgFull},
+	}
+	encodeRunner, encodeErr := NewModeCipherRunner(encodeArgMap, parser.Encode)
+	assert.NoError(t, encodeErr)
+	assert.IsType(t, &ContainerCipherRunner{}, encodeRunner)
+	assert.NoError(t, encodeRunner.Run())
+	for i, decodeArgMap := range decodeArgMaps {
+		// when
+		decodeRunner, decodeErr := NewModeCipherRunner(decodeArgMap, parser.Decode)
+		assert.NoError(t, decodeErr)
+		resultErr := decodeRunner.Run()
+		// then
+		assert.Equal(t, expectedErrs[i], resultErr, decodeArgMap)
+		if resultErr == nil {
+			decoded, _ := os.ReadFile(decodedPath)
+			assert.Equal(t, "Attack at dawn!", string(decoded))
+		}
+	}
 }
@@ -0,0 +1,304 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mat-sik/encoder-decoder/internal/ciphers"
+	"github.com/mat-sik/encoder-decoder/internal/parser"
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
+)
+
+// Command is a git-style subcommand of the binary, which owns its set of flags.
+type Command struct {
+	Name        string
+	Description string
+	Flags       []parser.Flag
+	run         func(argMap map[string]string, stdout io.Writer) error
+}
+
+// Exit codes of the binary, so that the scripts can tell the failures apart.
+const (
+	ExitOK           = 0 // The input has been transformed.
+	ExitFailure      = 1 // An unexpected failure has occurred.
+	ExitUsage        = 2 // The arguments are missing, unknown or invalid.
+	ExitInvalidInput = 3 // The input could not be transformed, e.g. it is not valid UTF-8.
+	ExitIO           = 4 // The input or the output could not be opened, read, written or closed.
+)
+
+// ExitCodeDefinition describes an exit code for the help output.
+type ExitCodeDefinition struct {
+	Code        int
+	Description string
+}
+
+var ExitCodeDefinitions = []ExitCodeDefinition{
+	{ExitOK, "success"},
+	{ExitFailure, "unexpected failure"},
+	{ExitUsage, "missing, unknown or invalid arguments"},
+	{ExitInvalidInput, "input which cannot be transformed, e.g. invalid UTF-8, a wrong key or a corrupted container"},
+	{ExitIO, "input or output which cannot be opened, read, written or closed"},
+}
+
+var cipherFlags = []parser.Flag{parser.ChosenAlg, parser.Key, parser.Alphabet, parser.ChosenUnit, parser.Invalid, parser.In}
+
+var Commands = []Command{
+	{
+		Name:        string(parser.Encode),
+		Description: "encodes the input with the algorithm",
+		Flags:       append(slices.Clone(cipherFlags), parser.BufferSize, parser.Parallel, parser.Container, parser.Out),
+		run:         newModeRun(parser.Encode),
+	},
+	{
+		Name:        string(parser.Decode),
+		Description: "decodes the input with the algorithm, or with the algorithms described by the container",
+		Flags:       append(slices.Clone(cipherFlags), parser.BufferSize, parser.Parallel, parser.Out),
+		run:         newModeRun(parser.Decode),
+	},
+	{
+		Name:        "verify",
+		Description: "checks that decoding the encoded input restores it",
+		Flags:       cipherFlags,
+		run:         runVerify,
+	},
+	{
+		Name:        "crack",
+		Description: "recovers the key of the Caesar or the Vigenère cipher the input has been encoded with",
+		Flags: []parser.Flag{
+			parser.ChosenAlg, parser.Alphabet, parser.Language, parser.Top, parser.MaxKeyLength, parser.DecodeBest,
+			parser.In, parser.Out,
+		},
+		run: runCrack,
+	},
+	{
+		Name:        "list",
+		Description: "lists the available algorithms",
+		run:         runList,
+	},
+}
+
+// Run runs the command named by the first argument. For compatibility, when the first argument is not
+// a command, the encode or the decode command is chosen with the mode flag.
+func Run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return WriteHelp(stdout)
+	}
+	if command, ok := GetCommand(args[0]); ok {
+		return command.Run(args[1:], stdout)
+	}
+	return runCompatibilityMode(args, stdout)
+}
+
+func GetCommand(name string) (Command, bool) {
+	for _, command := range Commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+func (command Command) Run(args []string, stdout io.Writer) error {
+	argMap, err := command.parse(args, command.flagDefinitions())
+	if err != nil {
+		return err
+	}
+	if parser.IsHelpRequested(argMap) {
+		return command.WriteHelp(stdout, argMap)
+	}
+	return command.run(argMap, stdout)
+}
+
+// flagDefinitions include the long-only flags of the algorithm parameters, when the command takes them.
+func (command Command) flagDefinitions() []parser.FlagDefinition {
+	flagDefinitions := parser.SelectFlagDefinitions(append([]parser.Flag{parser.Help}, command.Flags...)...)
+	if !command.takesParams() {
+		return flagDefinitions
+	}
+	for _, flagDefinition := range parser.ParamFlagDefinitions() {
+		if flagDefinition.Flag == "" {
+			flagDefinitions = append(flagDefinitions, flagDefinition)
+		}
+	}
+	return flagDefinitions
+}
+
+// parse rejects the flags which do not belong to the command.
+func (command Command) parse(args []string, flagDefinitions []parser.FlagDefinition) (map[string]string, error) {
+	argMap, err := parser.ParseFlags(args, flagDefinitions)
+	if err != nil {
+		return nil, err
+	}
+	for flag := range argMap {
+		if _, ok := parser.FindFlagDefinition(flagDefinitions, flag); !ok {
+			return nil, &ErrUnknownFlag{flag, command.Name}
+		}
+	}
+	return argMap, nil
+}
+
+func runCompatibilityMode(args []string, stdout io.Writer) error {
+	argMap, err := parser.Parse(args)
+	if err != nil {
+		return err
+	}
+	mode, err := parser.GetModeValue(argMap)
+	var errMissingFlag *parser.ErrMissingFlag
+	switch {
+	case errors.As(err, &errMissingFlag) && parser.IsHelpRequested(argMap):
+		if stages, stagesErr := parser.GetPipelineValue(argMap); stagesErr == nil {
+			return parser.WritePipelineHelp(stdout, stages)
+		}
+		return WriteHelp(stdout)
+	case errors.As(err, &errMissingFlag) && strings.HasPrefix(args[0], "-"):
+		return ErrMissingCommand
+	case errors.As(err, &errMissingFlag):
+		return &ErrUnknownCommand{args[0]}
+	case err != nil:
+		return err
+	}
+	command, _ := GetCommand(string(mode))
+	flagDefinitions := append(command.flagDefinitions(), parser.SelectFlagDefinitions(parser.ChosenMode)...)
+	if argMap, err = command.parse(args, flagDefinitions); err != nil {
+		return err
+	}
+	if parser.IsHelpRequested(argMap) {
+		return command.WriteHelp(stdout, argMap)
+	}
+	return command.run(argMap, stdout)
+}
+
+func newModeRun(mode parser.Mode) func(map[string]string, io.Writer) error {
+	return func(argMap map[string]string, _ io.Writer) error {
+		cipherRunner, err := ciphers.NewModeCipherRunner(argMap, mode)
+		if err != nil {
+			return err
+		}
+		return cipherRunner.Run()
+	}
+}
+
+// runVerify compares the digests of the input and of the input encoded and decoded back, so that the input
+// of any size is verified in a single streaming pass.
+func runVerify(argMap map[string]string, stdout io.Writer) error {
+	algCipher, cipherInput, err := ciphers.NewCipher(argMap)
+	if err != nil {
+		return err
+	}
+	inputHash := sha256.New()
+	roundTripHash := sha256.New()
+	var size int64
+	err = transformer.FilesTransfer(cipherInput.InPath, transformer.StdStreamPath, func(reader io.Reader, _ io.Writer) error {
+		encodingReader := algCipher.NewEncodingReader(io.TeeReader(reader, inputHash))
+		defer encodingReader.Close()
+		roundTripReader := algCipher.NewDecodingReader(encodingReader)
+		defer roundTripReader.Close()
+		var copyErr error
+		size, copyErr = io.Copy(roundTripHash, roundTripReader)
+		return copyErr
+	})
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(inputHash.Sum(nil), roundTripHash.Sum(nil)) {
+		return ErrVerificationFailed
+	}
+	_, err = fmt.Fprintf(stdout, "verified: decoding the encoded input restores all of its %d bytes\n", size)
+	return err
+}
+
+func runList(_ map[string]string, stdout io.Writer) error {
+	tabWriter := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	for _, algorithm := range cipher.Algorithms() {
+		name := algorithm.Name
+		if len(algorithm.Aliases) > 0 {
+			name += " (" + strings.Join(algorithm.Aliases, ", ") + ")"
+		}
+		_, _ = fmt.Fprintf(tabWriter, "%s\t%s\n", name, algorithm.Description)
+	}
+	return tabWriter.Flush()
+}
+
+func WriteHelp(writer io.Writer) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tabWriter, "Usage: encoder-decoder <command> [flags] [%s] [input] [output]\n\nCommands:\n",
+		parser.EndOfOptions)
+	for _, command := range Commands {
+		_, _ = fmt.Fprintf(tabWriter, "  %s\t%s\n", command.Name, command.Description)
+	}
+	_, _ = fmt.Fprintf(tabWriter, "\nRun encoder-decoder <command> %s for the flags of the command.\n\n", parser.HelpFull)
+	if err := tabWriter.Flush(); err != nil {
+		return err
+	}
+	if err := parser.WriteAlgsHelp(writer); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(writer)
+	return WriteExitCodesHelp(writer)
+}
+
+func WriteExitCodesHelp(writer io.Writer) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tabWriter, "Exit codes:")
+	for _, exitCodeDefinition := range ExitCodeDefinitions {
+		_, _ = fmt.Fprintf(tabWriter, "  %d\t%s\n", exitCodeDefinition.Code, exitCodeDefinition.Description)
+	}
+	return tabWriter.Flush()
+}
+
+// WriteHelp writes the help of the algorithms, if any were chosen, or the help of the command otherwise.
+func (command Command) WriteHelp(writer io.Writer, argMap map[string]string) error {
+	stages, err := parser.GetPipelineValue(argMap)
+	var errMissingFlag *parser.ErrMissingFlag
+	if err == nil {
+		return parser.WritePipelineHelp(writer, stages)
+	}
+	if !errors.As(err, &errMissingFlag) {
+		return err
+	}
+	_, _ = fmt.Fprintf(writer, "Usage: encoder-decoder %s [flags]\n  %s\n\n", command.Name, command.Description)
+	if err = parser.WriteFlagsHelp(writer, command.flagDefinitions()); err != nil {
+		return err
+	}
+	if command.takesParams() {
+		_, _ = fmt.Fprintln(writer)
+		if err = parser.WriteAlgsHelp(writer); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintln(writer)
+	return WriteExitCodesHelp(writer)
+}
+
+// takesParams tells whether the command runs the algorithms, rather than only naming them like crack does.
+func (command Command) takesParams() bool {
+	return slices.Contains(command.Flags, parser.Key)
+}
+
+type ErrUnknownCommand struct {
+	Command string
+}
+
+func (e *ErrUnknownCommand) Error() string {
+	return "unknown command: " + e.Command
+}
+
+type ErrUnknownFlag struct {
+	Flag    string
+	Command string
+}
+
+func (e *ErrUnknownFlag) Error() string {
+	return fmt.Sprintf("unknown flag: %s of command: %s", e.Flag, e.Command)
+}
+
+var (
+	ErrMissingCommand     = errors.New("missing command")
+	ErrVerificationFailed = errors.New("decoding the encoded input does not restore it")
+)
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mat-sik/encoder-decoder/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func Test_Run_encodeDecodeCommands(t *testing.T) {
+	// given
+	inPath := writeTestFile(t, "Hello, World!")
+	encodedPath := filepath.Join(t.TempDir(), "encoded.txt")
+	decodedPath := filepath.Join(t.TempDir(), "decoded.txt")
+	// when
+	encodeErr := Run([]string{"encode", "-a", "caesar", "-k", "3", "-l", "latin", inPath, encodedPath}, new(bytes.Buffer))
+	decodeErr := Run([]string{"decode", "-a", "caesar", "-k", "3", "-l", "latin", encodedPath, decodedPath}, new(bytes.Buffer))
+	// then
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	encoded, _ := os.ReadFile(encodedPath)
+	decoded, _ := os.ReadFile(decodedPath)
+	assert.Equal(t, "Khoor, Zruog!", string(encoded))
+	assert.Equal(t, "Hello, World!", string(decoded))
+}
+
+func Test_Run_inlineAffineKey(t *testing.T) {
+	// given
+	inPath := writeTestFile(t, "Hello, World!")
+	inputs := [][]string{
+		{"encode", "-a", "affine:5:8"},
+		{"encode", "-a", "affine:5:8,caesar:3", "-l", "latin"},
+		{"encode", "-a", "affine:5:8", "-a", "caesar:3", "-l", "latin"},
+		{"encode", "-a", "affine", "-k", "5,8"},
+	}
+	expectedEncoded := []string{"Rclla, Oaplx!", "Ufood, Rdsoa!", "Ufood, Rdsoa!", "Rclla, Oaplx!"}
+	for i, input := range inputs {
+		outPath := filepath.Join(t.TempDir(), "encoded.txt")
+		// when
+		err := Run(append(input, inPath, outPath), new(bytes.Buffer))
+		// then
+		assert.NoError(t, err, input)
+		encoded, _ := os.ReadFile(outPath)
+		assert.Equal(t, expectedEncoded[i], string(encoded), input)
+	}
+}
+
+func Test_Run_inlineDoubleTranspositionKeys(t *testing.T) {
+	// given
+	inPath := writeTestFile(t, "WEAREDISCOVEREDFLEEATONCE")
+	encodedPath := filepath.Join(t.TempDir(), "encoded.txt")
+	decodedPath := filepath.Join(t.TempDir(), "decoded.txt")
+	// when
+	encodeErr := Run([]string{"encode", "-c", "-a", "double:ZEBRAS:STRIPE", inPath, encodedPath}, new(bytes.Buffer))
+	decodeErr := Run([]string{"decode", "-k", "ZEBRAS,STRIPE", encodedPath, decodedPath}, new(bytes.Buffer))
+	// then
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	encoded, _ := os.ReadFile(encodedPath)
+	decoded, _ := os.ReadFile(decodedPath)
+	assert.NotContains(t, string(encoded), "WEAREDISCOVEREDFLEEATONCE")
+	assert.Equal(t, "WEAREDISCOVEREDFLEEATONCE", string(decoded))
+}
+
+func Test_Run_parallel(t *testing.T) {
+	// given
+	plaintext := strings.Repeat("Zażółć gęślą jaźń, Hello, World! ", 100_000)
+	inPath := writeTestFile(t, plaintext)
+	encodedPath := filepath.Join(t.TempDir(), "encoded.txt")
+	decodedPath := filepath.Join(t.TempDir(), "decoded.txt")
+	// when
+	encodeErr := Run([]string{"encode", "-p", "-c", "-a", "vigenere", "-k", "lemon", inPath, encodedPath}, new(bytes.Buffer))
+	decodeErr := Run([]string{"decode", "--parallel", "-k", "lemon", encodedPath, decodedPath}, new(bytes.Buffer))
+	// then
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	decoded, _ := os.ReadFile(decodedPath)
+	assert.True(t, plaintext == string(decoded))
+}
+
+func Test_Run_modeCompatibility(t *testing.T) {
+	// given
+	inPath := writeTestFile(t, "Hello, World!")
+	outPath := filepath.Join(t.TempDir(), "encoded.txt")
+	// when
+	err := Run([]string{"-m=encode", "-a=caesar", "-k=3", "-l=latin", "-i=" + inPath, "-o=" + outPath}, new(bytes.Buffer))
+	// then
+	assert.NoError(t, err)
+	encoded, _ := os.ReadFile(outPath)
+	assert.Equal(t, "Khoor, Zruog!", string(encoded))
+}
+
+func Test_Run_verify(t *testing.T) {
+	// given
+	inPath := writeTestFile(t, "Hello, World! ✈")
+	stdout := new(bytes.Buffer)
+	// when
+	err := Run([]string{"verify", "-a", "vigenere", "-k", "lemon", inPath}, stdout)
+	// then
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "verified")
+}
+
+func Test_Run_list(t *testing.T) {
+	// given
+	stdout := new(bytes.Buffer)
+	// when
+	err := Run([]string{"list"}, stdout)
+	// then
+	assert.NoError(t, err)
+	for _, algName := range parser.AlgNames() {
+		assert.Contains(t, stdout.String(), algName)
+	}
+}
+
+func Test_Run_errors(t *testing.T) {
+	// given
+	inputs := [][]string{
+		{"crypt", "-a", "caesar"},
+		{"-a", "caesar"},
+		{"list", "--algorithm=caesar"},
+		{"verify", "-a", "caesar", "-k", "3", "-o", "out.txt"},
+	}
+	expectedErrs := []error{
+		&ErrUnknownCommand{"crypt"},
+		ErrMissingCommand,
+		&ErrUnknownFlag{"--algorithm", "list"},
+		&ErrUnknownFlag{"-o", "verify"},
+	}
+	for i, input := range inputs {
+		// when
+		err := Run(input, new(bytes.Buffer))
+		// then
+		assert.Equal(t, expectedErrs[i], err, input)
+	}
+}
+
+func Test_Run_commandHelp(t *testing.T) {
+	// given
+	stdout := new(bytes.Buffer)
+	// when
+	err := Run([]string{"encode", "--help"}, stdout)
+	// then
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "Usage: encoder-decoder encode")
+	assert.Contains(t, stdout.String(), string(parser.KeyFull))
+	assert.NotContains(t, stdout.String(), string(parser.ChosenModeFull))
+	assert.Contains(t, stdout.String(), "Exit codes:")
+}
+
+func Test_WriteHelp_exitCodes(t *testing.T) {
+	// given
+	stdout := new(bytes.Buffer)
+	// when
+	err := WriteHelp(stdout)
+	// then
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "Exit codes:")
+	for _, exitCodeDefinition := range ExitCodeDefinitions {
+		assert.Regexp(t, fmt.Sprintf(`(?m)^  %d +%s$`, exitCodeDefinition.Code, exitCodeDefinition.Description), stdout.String())
+	}
+}
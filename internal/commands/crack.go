@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/mat-sik/encoder-decoder/internal/analysis"
+	"github.com/mat-sik/encoder-decoder/internal/parser"
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
+)
+
+// The candidates are scored on the sample at the start of the input only, its preview is even shorter.
+const (
+	crackSampleSize = 64 * 1024
+	previewLength   = 48
+)
+
+// runCrack prints the candidate keys of the Caesar cipher, or the key lengths and the key of the Vigenère
+// cipher, the input has been encoded with, and decodes the input with the best key when requested.
+// Without the alphabet, the keys of the Caesar cipher shift over the whole Unicode scalar value range.
+func runCrack(argMap map[string]string, stdout io.Writer) error {
+	alg, err := getCrackedAlg(argMap)
+	if err != nil {
+		return err
+	}
+	table, err := getFrequencyTable(parser.GetLanguageValue(argMap))
+	if err != nil {
+		return err
+	}
+	top, err := parser.GetTopValue(argMap)
+	if err != nil {
+		return err
+	}
+	maxKeyLength, err := parser.GetMaxKeyLengthValue(argMap)
+	if err != nil {
+		return err
+	}
+	var alphabets []*algorithms.Alphabet
+	if alphabetName, ok := parser.GetAlphabetValue(argMap); ok {
+		if alphabets, err = algorithms.GetAlphabets(alphabetName); err != nil {
+			return err
+		}
+	}
+	inPath, ok := parser.GetInValue(argMap)
+	if !ok {
+		inPath = transformer.StdStreamPath
+	}
+	outPath, ok := parser.GetOutValue(argMap)
+	decodeBest := parser.IsDecodeBestRequested(argMap)
+	switch {
+	case decodeBest && (!ok || outPath == transformer.StdStreamPath):
+		return ErrCrackOutput
+	case !decodeBest:
+		outPath = transformer.StdStreamPath
+	}
+	return transformer.FilesTransfer(inPath, outPath, func(reader io.Reader, writer io.Writer) error {
+		sampleBytes, sample, err := readSample(reader)
+		if err != nil {
+			return err
+		}
+		if len(sample) == 0 {
+			return ErrEmptySample
+		}
+		var bestCipher *cipher.Cipher
+		if alg == vigenereAlg {
+			bestCipher, err = crackVigenere(stdout, sample, table, top, maxKeyLength)
+		} else {
+			bestCipher, err = crackCaesar(stdout, sample, table, top, alphabets)
+		}
+		if err != nil || !decodeBest {
+			return err
+		}
+		return bestCipher.Decode(io.MultiReader(bytes.NewReader(sampleBytes), reader), writer)
+	})
+}
+
+// The algorithms the crack command recovers the keys of, the Caesar cipher by default.
+const (
+	caesarAlg   parser.Alg = "caesar"
+	vigenereAlg parser.Alg = "vigenere"
+)
+
+func getCrackedAlg(argMap map[string]string) (parser.Alg, error) {
+	stages, err := parser.GetPipelineValue(argMap)
+	var errMissingFlag *parser.ErrMissingFlag
+	switch {
+	case errors.As(err, &errMissingFlag):
+		return caesarAlg, nil
+	case err != nil:
+		return "", err
+	}
+	if len(stages) != 1 || len(stages[0].Params) != 0 || (stages[0].Alg != caesarAlg && stages[0].Alg != vigenereAlg) {
+		algs := make([]string, 0, len(stages))
+		for _, stage := range stages {
+			algs = append(algs, string(stage.Alg))
+		}
+		return "", &ErrUncrackableAlgorithm{strings.Join(algs, parser.StageSeparator)}
+	}
+	return stages[0].Alg, nil
+}
+
+func crackCaesar(
+	stdout io.Writer,
+	sample []rune,
+	table *analysis.FrequencyTable,
+	top int,
+	alphabets []*algorithms.Alphabet,
+) (*cipher.Cipher, error) {
+	var candidates []analysis.Candidate
+	if alphabets == nil {
+		candidates = analysis.CrackCaesar(sample, table)
+	} else {
+		candidates = analysis.CrackAlphabetCaesar(sample, table, alphabets...)
+	}
+	if err := writeCandidates(stdout, candidates[:min(top, len(candidates))]); err != nil {
+		return nil, err
+	}
+	return newCrackedCipher(candidates[0].Key, alphabets)
+}
+
+func crackVigenere(stdout io.Writer, sample []rune, table *analysis.FrequencyTable, top int, maxKeyLength int) (*cipher.Cipher, error) {
+	solution, err := analysis.CrackVigenere(sample, table, maxKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeVigenereSolution(stdout, solution, top); err != nil {
+		return nil, err
+	}
+	return cipher.NewVigenere(solution.Key)
+}
+
+func getFrequencyTable(language string) (*analysis.FrequencyTable, error) {
+	if table, ok := analysis.GetFrequencyTable(language); ok {
+		return table, nil
+	}
+	file, err := os.Open(language)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &ErrUnknownLanguage{language}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return analysis.ParseFrequencyTable(file)
+}
+
+// readSample returns the bytes read from the start of the reader along with their runes, leaving out
+// the rune cut off at the end of the sample.
+func readSample(reader io.Reader) ([]byte, []rune, error) {
+	sampleBytes := make([]byte, crackSampleSize)
+	n, err := io.ReadFull(reader, sampleBytes)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, err
+	}
+	sampleBytes = sampleBytes[:n]
+	sample := make([]rune, 0, n)
+	for i := 0; i < n; {
+		r, size := utf8.DecodeRune(sampleBytes[i:])
+		if r == utf8.RuneError && size <= 1 {
+			if !utf8.FullRune(sampleBytes[i:]) && n == crackSampleSize {
+				break
+			}
+			return nil, nil, &transformer.ErrInvalidUTF8{Offset: int64(i), Bytes: []byte{sampleBytes[i]}}
+		}
+		sample = append(sample, r)
+		i += size
+	}
+	return sampleBytes, sample, nil
+}
+
+func newCrackedCipher(key int, alphabets []*algorithms.Alphabet) (*cipher.Cipher, error) {
+	if alphabets != nil {
+		return cipher.NewAlphabetCaesar(key, alphabets...), nil
+	}
+	return cipher.NewCaesar(key)
+}
+
+func writeCandidates(writer io.Writer, candidates []analysis.Candidate) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tabWriter, "rank\tkey\tchi-squared\tconfidence\tpreview")
+	for i, candidate := range candidates {
+		_, _ = fmt.Fprintf(tabWriter, "%d\t%d\t%.2f\t%.1f%%\t%s\n",
+			i+1, candidate.Key, candidate.ChiSquared, 100*candidate.Confidence, preview(candidate.Plaintext))
+	}
+	return tabWriter.Flush()
+}
+
+func writeVigenereSolution(writer io.Writer, solution *analysis.VigenereSolution, top int) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tabWriter, "rank\tkey length\tscore\tindex of coincidence\tkasiski")
+	for i, keyLength := range solution.KeyLengths[:min(top, len(solution.KeyLengths))] {
+		_, _ = fmt.Fprintf(tabWriter, "%d\t%d\t%.3f\t%.4f\t%.3f\n",
+			i+1, keyLength.Length, keyLength.Score, keyLength.IndexOfCoincidence, keyLength.Kasiski)
+	}
+	_, _ = fmt.Fprintf(tabWriter, "\nkey:\t%s\npreview:\t%s\n", solution.Key, preview(solution.Plaintext))
+	return tabWriter.Flush()
+}
+
+// preview replaces the line breaks and the other unprintable runes, so that a candidate takes one line.
+func preview(plaintext []rune) string {
+	var builder strings.Builder
+	for _, r := range plaintext[:min(previewLength, len(plaintext))] {
+		switch {
+		case unicode.IsSpace(r):
+			builder.WriteRune(' ')
+		case !unicode.IsPrint(r):
+			builder.WriteRune(utf8.RuneError)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+type ErrUnknownLanguage struct {
+	Language string
+}
+
+func (e *ErrUnknownLanguage) Error() string {
+	return fmt.Sprintf("unknown language: %s, available languages: %s, %s or the path of a frequency table",
+		e.Language, analysis.EnglishName, analysis.PolishName)
+}
+
+type ErrUncrackableAlgorithm struct {
+	Algorithm string
+}
+
+func (e *ErrUncrackableAlgorithm) Error() string {
+	return fmt.Sprintf("cannot crack: %s, available algorithms: %s, %s", e.Algorithm, caesarAlg, vigenereAlg)
+}
+
+var (
+	ErrCrackOutput = errors.New("decoding with the best candidate requires an output file, " +
+		"the standard output is taken by the candidates")
+	ErrEmptySample = errors.New("input is empty, there is nothing to crack")
+)
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const crackPlaintext = "It was the best of times, it was the worst of times, it was the age of wisdom, " +
+	"it was the age of foolishness, it was the epoch of belief, it was the epoch of incredulity."
+
+func Test_Run_crack(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	inPath := writeTestFile(t, crackPlaintext)
+	encodedPath := filepath.Join(dir, "encoded.txt")
+	decodedPath := filepath.Join(dir, "decoded.txt")
+	assert.NoError(t, Run([]string{"encode", "-a", "caesar", "-k", "-17", inPath, encodedPath}, new(bytes.Buffer)))
+	stdout := new(bytes.Buffer)
+	// when
+	err := Run([]string{"crack", "--top=2", "--decode", encodedPath, decodedPath}, stdout)
+	// then
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, `^1\s+-17\s+`, lines[1])
+	assert.Contains(t, lines[1], "It was the best of times")
+	decoded, _ := os.ReadFile(decodedPath)
+	assert.Equal(t, crackPlaintext, string(decoded))
+}
+
+func Test_Run_crack_vigenere(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	plaintext := strings.Repeat(crackPlaintext+" ", 4)
+	inPath := writeTestFile(t, plaintext)
+	encodedPath := filepath.Join(dir, "encoded.txt")
+	decodedPath := filepath.Join(dir, "decoded.txt")
+	assert.NoError(t, Run([]string{"encode", "-a", "vigenere", "-k", "dickens", inPath, encodedPath}, new(bytes.Buffer)))
+	stdout := new(bytes.Buffer)
+	// when
+	err := Run([]string{"crack", "-a", "vigenère", "-t", "3", "-n", "12", "-d", encodedPath, decodedPath}, stdout)
+	// then
+	assert.NoError(t, err)
+	assert.Regexp(t, `\nkey:\s+dickens\n`, stdout.String())
+	assert.Contains(t, stdout.String(), "It was the best of times")
+	decoded, _ := os.ReadFile(decodedPath)
+	assert.Equal(t, plaintext, string(decoded))
+}
+
+func Test_Run_crack_errors(t *testing.T) {
+	// given
+	inPath := writeTestFile(t, crackPlaintext)
+	emptyPath := writeTestFile(t, "")
+	inputs := [][]string{
+		{"crack", "-L", "klingon", inPath},
+		{"crack", "-d", inPath},
+		{"crack", "-d", inPath, "-"},
+		{"crack", emptyPath},
+		{"crack", "-a", "mirror", inPath},
+		{"crack", "-a", "caesar,vigenere", inPath},
+	}
+	expectedErrs := []error{
+		&ErrUnknownLanguage{"klingon"},
+		ErrCrackOutput,
+		ErrCrackOutput,
+		ErrEmptySample,
+		&ErrUncrackableAlgorithm{"mirror"},
+		&ErrUncrackableAlgorithm{"caesar,vigenere"},
+	}
+	for i, input := range inputs {
+		// when
+		err := Run(input, new(bytes.Buffer))
+		// then
+		assert.Equal(t, expectedErrs[i], err, input)
+	}
+}
+
+func Test_readSample_cutRune(t *testing.T) {
+	// given
+	input := strings.Repeat("a", crackSampleSize-1) + "ż"
+	// when
+	sampleBytes, sample, err := readSample(strings.NewReader(input))
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, sampleBytes, crackSampleSize)
+	assert.Len(t, sample, crackSampleSize-1)
+}
+
+func Test_preview(t *testing.T) {
+	// given
+	input := []rune("line\nbreak\x00" + strings.Repeat("x", previewLength))
+	// when
+	result := preview(input)
+	// then
+	assert.Equal(t, "line break�"+strings.Repeat("x", previewLength-11), result)
+}
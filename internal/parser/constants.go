@@ -2,25 +2,70 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
 )
 
+// Alg is the name of an algorithm of the cipher registry.
 type Alg string
 
+// newAlg resolves the aliases to the names the algorithms are registered with.
+func newAlg(algString string) (Alg, error) {
+	algorithm, ok := cipher.Lookup(algString)
+	if !ok {
+		return "", &ErrUnknownAlgorithm{algString}
+	}
+	return Alg(algorithm.Name), nil
+}
+
+// Separators of the pipeline syntax: caesar:3,mirror,vigenere:KEY.
 const (
-	Caesar Alg = "caesar"
-	Mirror Alg = "mirror"
+	StageSeparator      = ","
+	StageValueSeparator = ":"
 )
 
-func newAlg(algString string) (Alg, error) {
-	switch Alg(algString) {
-	case Caesar:
-		return Caesar, nil
-	case Mirror:
-		return Mirror, nil
-	default:
-		return "", &ErrUnknownAlgorithm{algString}
+// Stage is an algorithm of a pipeline along with the parameter values given inline, keyed by the
+// parameter names.
+type Stage struct {
+	Alg    Alg
+	Params map[string]string
+}
+
+// newPipeline parses the stages separated with the StageSeparator. The value following the
+// StageValueSeparator sets the first parameter of the algorithm of the stage.
+func newPipeline(pipelineString string) ([]Stage, error) {
+	stageStrings := strings.Split(pipelineString, StageSeparator)
+	stages := make([]Stage, 0, len(stageStrings))
+	for _, stageString := range stageStrings {
+		algString, value, hasValue := strings.Cut(stageString, StageValueSeparator)
+		alg, err := newAlg(algString)
+		if err != nil {
+			return nil, err
+		}
+		params := make(map[string]string)
+		if hasValue {
+			algorithm, _ := cipher.Lookup(string(alg))
+			if len(algorithm.Params) == 0 {
+				return nil, &ErrInvalidStage{stageString, "the algorithm takes no parameters"}
+			}
+			params[algorithm.Params[0].Name] = value
+		}
+		stages = append(stages, Stage{alg, params})
 	}
+	return stages, nil
+}
+
+type ErrInvalidStage struct {
+	Stage  string
+	Reason string
+}
+
+func (e *ErrInvalidStage) Error() string {
+	return fmt.Sprintf("invalid pipeline stage: %s, %s", e.Stage, e.Reason)
 }
 
 type ErrUnknownAlgorithm struct {
@@ -57,47 +102,196 @@ func (e *ErrUnknownMode) Error() string {
 	return "unknown mode: " + e.Mode
 }
 
+// Unit tells whether the cipher operates on UTF-8 encoded runes or on raw bytes.
+type Unit string
+
+const (
+	Rune Unit = "rune"
+	Byte Unit = "byte"
+)
+
+func newUnit(unitString string) (Unit, error) {
+	switch Unit(unitString) {
+	case Rune:
+		return Rune, nil
+	case Byte:
+		return Byte, nil
+	default:
+		return "", &ErrUnknownUnit{unitString}
+	}
+}
+
+type ErrUnknownUnit struct {
+	Unit string
+}
+
+func (e *ErrUnknownUnit) Error() string {
+	return "unknown unit: " + e.Unit
+}
+
 type Flag string
 
 const (
-	ChosenMode     Flag = "-m"
-	ChosenModeFull Flag = "--mode"
-	In             Flag = "-i"
-	InFull         Flag = "--input"
-	Out            Flag = "-o"
-	OutFull        Flag = "--output"
-	ChosenAlg      Flag = "-a"
-	ChosenAlgFull  Flag = "--algorithm"
-	Key            Flag = "-k"
-	KeyFull        Flag = "--key"
+	Help             Flag = "-h"
+	HelpFull         Flag = "--help"
+	ChosenMode       Flag = "-m"
+	ChosenModeFull   Flag = "--mode"
+	In               Flag = "-i"
+	InFull           Flag = "--input"
+	Out              Flag = "-o"
+	OutFull          Flag = "--output"
+	ChosenAlg        Flag = "-a"
+	ChosenAlgFull    Flag = "--algorithm"
+	Key              Flag = "-k"
+	KeyFull          Flag = "--key"
+	Alphabet         Flag = "-l"
+	AlphabetFull     Flag = "--alphabet"
+	ChosenUnit       Flag = "-u"
+	ChosenUnitFull   Flag = "--unit"
+	Container        Flag = "-c"
+	ContainerFull    Flag = "--container"
+	Language         Flag = "-L"
+	LanguageFull     Flag = "--language"
+	Top              Flag = "-t"
+	TopFull          Flag = "--top"
+	DecodeBest       Flag = "-d"
+	DecodeBestFull   Flag = "--decode"
+	MaxKeyLength     Flag = "-n"
+	MaxKeyLengthFull Flag = "--max-key-length"
+	Parallel         Flag = "-p"
+	ParallelFull     Flag = "--parallel"
+	BufferSize       Flag = "-b"
+	BufferSizeFull   Flag = "--buffer-size"
+	Invalid          Flag = "-e"
+	InvalidFull      Flag = "--invalid"
 )
 
-func GetIntKeyValue(argMap map[string]string) (int, error) {
-	intString, err := getKeyValue(argMap)
-	if err != nil {
-		return -1, err
+// Defaults of the crack command flags.
+const (
+	DefaultLanguage     = "english"
+	DefaultTop          = 5
+	DefaultMaxKeyLength = 20
+)
+
+func GetInValue(argMap map[string]string) (string, bool) {
+	return getOptionalFlagValue(argMap, In, InFull)
+}
+
+func GetOutValue(argMap map[string]string) (string, bool) {
+	return getOptionalFlagValue(argMap, Out, OutFull)
+}
+
+// GetParamValues collects the values of the algorithm parameter flags, keyed by the parameter names.
+func GetParamValues(argMap map[string]string) map[string]string {
+	paramValues := make(map[string]string)
+	for _, flagDefinition := range ParamFlagDefinitions() {
+		if value, ok := getOptionalFlagValue(argMap, flagDefinition.Flag, flagDefinition.FullFlag); ok {
+			paramValues[paramName(flagDefinition.FullFlag)] = value
+		}
+	}
+	return paramValues
+}
+
+func GetAlphabetValue(argMap map[string]string) (string, bool) {
+	return getOptionalFlagValue(argMap, Alphabet, AlphabetFull)
+}
+
+// GetLanguageValue returns the name of a built-in frequency table or the path of a custom one.
+func GetLanguageValue(argMap map[string]string) string {
+	if language, ok := getOptionalFlagValue(argMap, Language, LanguageFull); ok {
+		return language
 	}
-	return strconv.Atoi(intString)
+	return DefaultLanguage
+}
+
+func GetTopValue(argMap map[string]string) (int, error) {
+	return getPositiveIntValue(argMap, Top, TopFull, DefaultTop)
 }
 
-func GetInValue(argMap map[string]string) (string, error) {
-	return getFlagValue(argMap, In, InFull)
+// GetMaxKeyLengthValue returns the length of the longest key tried when cracking the Vigenère cipher.
+func GetMaxKeyLengthValue(argMap map[string]string) (int, error) {
+	return getPositiveIntValue(argMap, MaxKeyLength, MaxKeyLengthFull, DefaultMaxKeyLength)
 }
 
-func GetOutValue(argMap map[string]string) (string, error) {
-	return getFlagValue(argMap, Out, OutFull)
+// GetBufferSizeValue returns the size of the reads from the input, which has to fit the longest rune and
+// to fit within transformer.MaxBufferSize.
+func GetBufferSizeValue(argMap map[string]string) (int, error) {
+	bufferSize, err := getPositiveIntValue(argMap, BufferSize, BufferSizeFull, transformer.ReadBufferSize)
+	if err == nil && (bufferSize < transformer.MinBufferSize || bufferSize > transformer.MaxBufferSize) {
+		expected := fmt.Sprintf("an integer within %d-%d", transformer.MinBufferSize, transformer.MaxBufferSize)
+		return 0, &ErrInvalidFlagValue{BufferSize, BufferSizeFull, strconv.Itoa(bufferSize), expected}
+	}
+	return bufferSize, err
 }
 
-func getKeyValue(argMap map[string]string) (string, error) {
-	return getFlagValue(argMap, Key, KeyFull)
+// GetInvalidPolicyValue defaults to transformer.InvalidError when the policy is not provided.
+func GetInvalidPolicyValue(argMap map[string]string) (transformer.InvalidPolicy, error) {
+	value, ok := getOptionalFlagValue(argMap, Invalid, InvalidFull)
+	if !ok {
+		return transformer.InvalidError, nil
+	}
+	policy := transformer.InvalidPolicy(value)
+	if !slices.Contains(transformer.InvalidPolicies, policy) {
+		return "", &ErrInvalidFlagValue{Invalid, InvalidFull, value, "one of: " + invalidPoliciesString()}
+	}
+	return policy, nil
+}
+
+func invalidPoliciesString() string {
+	policies := make([]string, 0, len(transformer.InvalidPolicies))
+	for _, policy := range transformer.InvalidPolicies {
+		policies = append(policies, string(policy))
+	}
+	return strings.Join(policies, ", ")
+}
+
+func getPositiveIntValue(argMap map[string]string, flag Flag, fullFlag Flag, defaultValue int) (int, error) {
+	valueString, ok := getOptionalFlagValue(argMap, flag, fullFlag)
+	if !ok {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(valueString)
+	if err != nil || value < 1 {
+		return 0, &ErrInvalidFlagValue{flag, fullFlag, valueString, "a positive integer"}
+	}
+	return value, nil
+}
+
+func IsDecodeBestRequested(argMap map[string]string) bool {
+	_, ok := getOptionalFlagValue(argMap, DecodeBest, DecodeBestFull)
+	return ok
+}
+
+func IsContainerRequested(argMap map[string]string) bool {
+	_, ok := getOptionalFlagValue(argMap, Container, ContainerFull)
+	return ok
+}
+
+func IsParallelRequested(argMap map[string]string) bool {
+	_, ok := getOptionalFlagValue(argMap, Parallel, ParallelFull)
+	return ok
 }
 
 func GetModeValue(argMap map[string]string) (Mode, error) {
 	return getMappedValue(argMap, ChosenMode, ChosenModeFull, newMode)
 }
 
-func GetAlgValue(argMap map[string]string) (Alg, error) {
-	return getMappedValue(argMap, ChosenAlg, ChosenAlgFull, newAlg)
+// GetPipelineValue returns the stages of the pipeline, which consists of a single stage when only one
+// algorithm is chosen.
+func GetPipelineValue(argMap map[string]string) ([]Stage, error) {
+	value, err := getFlagValue(argMap, ChosenAlg, ChosenAlgFull)
+	if err != nil {
+		return nil, err
+	}
+	return newPipeline(value)
+}
+
+// GetUnitValue defaults to Rune when the unit is not provided.
+func GetUnitValue(argMap map[string]string) (Unit, error) {
+	if _, ok := getOptionalFlagValue(argMap, ChosenUnit, ChosenUnitFull); !ok {
+		return Rune, nil
+	}
+	return getMappedValue(argMap, ChosenUnit, ChosenUnitFull, newUnit)
 }
 
 func getMappedValue[T ~string](argMap map[string]string, flag Flag, fullFlag Flag, newConst func(string) (T, error)) (T, error) {
@@ -125,6 +319,11 @@ func getFlagValue(argMap map[string]string, flag Flag, fullFlag Flag) (string, e
 	return value, nil
 }
 
+func getOptionalFlagValue(argMap map[string]string, flag Flag, fullFlag Flag) (string, bool) {
+	value, err := getFlagValue(argMap, flag, fullFlag)
+	return value, err == nil
+}
+
 type ErrMissingFlag struct {
 	RequiredFlag     Flag
 	RequiredFlagFull Flag
@@ -133,3 +332,14 @@ type ErrMissingFlag struct {
 func (err *ErrMissingFlag) Error() string {
 	return fmt.Sprintf("required flag: %s or %s is missing", err.RequiredFlag, err.RequiredFlagFull)
 }
+
+type ErrInvalidFlagValue struct {
+	Flag     Flag
+	FlagFull Flag
+	Value    string
+	Expected string
+}
+
+func (err *ErrInvalidFlagValue) Error() string {
+	return fmt.Sprintf("invalid value: %s of flag: %s or %s, expected %s", err.Value, err.Flag, err.FlagFull, err.Expected)
+}
@@ -1,10 +1,23 @@
 package parser
 
 import (
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
+func init() {
+	cipher.Register(cipher.Algorithm{
+		Name:        "test-mirror",
+		Description: "mirrors the runes without any parameters, used by the tests only",
+		Units:       []cipher.Unit{cipher.RuneUnit},
+		New: func(cipher.Unit, cipher.Params) (*cipher.Cipher, error) {
+			return cipher.NewMirror(), nil
+		},
+	})
+}
+
 func Test_getModeValue(t *testing.T) {
 	// given
 	argMap := map[string]string{
@@ -18,3 +31,174 @@ func Test_getModeValue(t *testing.T) {
 	assert.Equal(t, expectedMode, resultMode)
 	assert.Equal(t, expectedErr, resultErr)
 }
+
+func Test_GetParamValues(t *testing.T) {
+	// given
+	argMap := map[string]string{
+		"-k":         "3",
+		"--alphabet": "latin",
+		"-i":         "in.txt",
+	}
+	expectedValues := map[string]string{
+		"key":      "3",
+		"alphabet": "latin",
+	}
+	// when
+	resultValues := GetParamValues(argMap)
+	// then
+	assert.Equal(t, expectedValues, resultValues)
+}
+
+func Test_GetPipelineValue(t *testing.T) {
+	// given
+	argMap := map[string]string{
+		"--algorithm": "shift:3,mirror,vigenere:lemon:lime",
+	}
+	expectedStages := []Stage{
+		{"caesar", map[string]string{"key": "3"}},
+		{"mirror", map[string]string{}},
+		{"vigenere", map[string]string{"key": "lemon:lime"}},
+	}
+	// when
+	resultStages, resultErr := GetPipelineValue(argMap)
+	// then
+	assert.NoError(t, resultErr)
+	assert.Equal(t, expectedStages, resultStages)
+}
+
+func Test_GetPipelineValue_errors(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{"-a": "enigma"},
+		{"-a": "caesar:3,,mirror"},
+		{"-a": "caesar:3,test-mirror:1"},
+		{},
+	}
+	expectedErrs := []error{
+		&ErrUnknownAlgorithm{"enigma"},
+		&ErrUnknownAlgorithm{""},
+		&ErrInvalidStage{"test-mirror:1", "the algorithm takes no parameters"},
+		&ErrMissingFlag{ChosenAlg, ChosenAlgFull},
+	}
+	for i, argMap := range argMaps {
+		// when
+		_, resultErr := GetPipelineValue(argMap)
+		// then
+		assert.Equal(t, expectedErrs[i], resultErr, argMap)
+	}
+}
+
+func Test_GetUnitValue(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{},
+		{"-u": "byte"},
+		{"--unit": "rune"},
+		{"--unit": "bit"},
+	}
+	expectedUnits := []Unit{Rune, Byte, Rune, ""}
+	expectedErrs := []error{nil, nil, nil, &ErrUnknownUnit{"bit"}}
+	for i, argMap := range argMaps {
+		// when
+		resultUnit, resultErr := GetUnitValue(argMap)
+		// then
+		assert.Equal(t, expectedUnits[i], resultUnit)
+		assert.Equal(t, expectedErrs[i], resultErr)
+	}
+}
+
+func Test_GetTopValue(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{},
+		{"-t": "3"},
+		{"--top": "0"},
+		{"--top": "three"},
+	}
+	expectedTops := []int{DefaultTop, 3, 0, 0}
+	expectedErrs := []error{
+		nil,
+		nil,
+		&ErrInvalidFlagValue{Top, TopFull, "0", "a positive integer"},
+		&ErrInvalidFlagValue{Top, TopFull, "three", "a positive integer"},
+	}
+	for i, argMap := range argMaps {
+		// when
+		resultTop, resultErr := GetTopValue(argMap)
+		// then
+		assert.Equal(t, expectedTops[i], resultTop, argMap)
+		assert.Equal(t, expectedErrs[i], resultErr, argMap)
+	}
+}
+
+func Test_GetMaxKeyLengthValue(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{},
+		{"-n": "12"},
+		{"--max-key-length": "-1"},
+	}
+	expectedLengths := []int{DefaultMaxKeyLength, 12, 0}
+	expectedErrs := []error{
+		nil,
+		nil,
+		&ErrInvalidFlagValue{MaxKeyLength, MaxKeyLengthFull, "-1", "a positive integer"},
+	}
+	for i, argMap := range argMaps {
+		// when
+		resultLength, resultErr := GetMaxKeyLengthValue(argMap)
+		// then
+		assert.Equal(t, expectedLengths[i], resultLength, argMap)
+		assert.Equal(t, expectedErrs[i], resultErr, argMap)
+	}
+}
+
+func Test_GetBufferSizeValue(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{},
+		{"-b": "65536"},
+		{"--buffer-size": "3"},
+		{"--buffer-size": "big"},
+		{"-b": "1000000000000"},
+	}
+	expectedSizes := []int{transformer.ReadBufferSize, 65536, 0, 0, 0}
+	expectedErrs := []error{
+		nil,
+		nil,
+		&ErrInvalidFlagValue{BufferSize, BufferSizeFull, "3", "an integer within 4-16777216"},
+		&ErrInvalidFlagValue{BufferSize, BufferSizeFull, "big", "a positive integer"},
+		&ErrInvalidFlagValue{BufferSize, BufferSizeFull, "1000000000000", "an integer within 4-16777216"},
+	}
+	for i, argMap := range argMaps {
+		// when
+		resultSize, resultErr := GetBufferSizeValue(argMap)
+		// then
+		assert.Equal(t, expectedSizes[i], resultSize, argMap)
+		assert.Equal(t, expectedErrs[i], resultErr, argMap)
+	}
+}
+
+func Test_GetInvalidPolicyValue(t *testing.T) {
+	// given
+	argMaps := []map[string]string{
+		{},
+		{"-e": "replace"},
+		{"--invalid": "passthrough"},
+		{"--invalid": "ignore"},
+	}
+	expectedPolicies := []transformer.InvalidPolicy{transformer.InvalidError, transformer.InvalidReplace, transformer.InvalidPassthrough, ""}
+	expectedErrs := []error{
+		nil,
+		nil,
+		nil,
+		&ErrInvalidFlagValue{Invalid, InvalidFull, "ignore", "one of: error, replace, skip, passthrough"},
+	}
+	for i, argMap := range argMaps {
+		// when
+		resultPolicy, resultErr := GetInvalidPolicyValue(argMap)
+		// then
+		assert.Equal(t, expectedPolicies[i], resultPolicy, argMap)
+		assert.Equal(t, expectedErrs[i], resultErr, argMap)
+	}
+}
@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
+)
+
+// FlagDefinition describes a flag for the help output.
+type FlagDefinition struct {
+	Flag        Flag
+	FullFlag    Flag
+	Value       string // Placeholder of the value, empty for the flags without any.
+	Description string
+	Default     string
+}
+
+var FlagDefinitions = []FlagDefinition{
+	{Help, HelpFull, "", "print the help, or the help of the algorithm given with " + string(ChosenAlgFull), ""},
+	{ChosenMode, ChosenModeFull, "<mode>", fmt.Sprintf("%s or %s, kept for compatibility with the commands of the same names", Encode, Decode), ""},
+	{ChosenAlg, ChosenAlgFull, "<algorithm>", "algorithm to use, or a pipeline of algorithms such as caesar:3,mirror, repeatable", ""},
+	{Key, KeyFull, "<key>", "key of the algorithm, its format depends on the algorithm", ""},
+	{Alphabet, AlphabetFull, "<alphabet>", "alphabet to restrict the algorithm to: latin, cyrillic, greek, digits, such names joined with + or the runes of a custom alphabet", ""},
+	{ChosenUnit, ChosenUnitFull, "<unit>", fmt.Sprintf("%s to transform UTF-8 text or %s to transform any data", Rune, Byte), string(Rune)},
+	{Invalid, InvalidFull, "<policy>", "what to do with the input which is not valid UTF-8: " + invalidPoliciesString(), string(transformer.InvalidError)},
+	{BufferSize, BufferSizeFull, "<bytes>", "size of the reads from the input and of the aes-gcm chunks", strconv.Itoa(transformer.ReadBufferSize)},
+	{Parallel, ParallelFull, "", "transform large chunks of the input concurrently on all the processors", ""},
+	{Container, ContainerFull, "", "wrap the encoded output in a container describing the algorithms, which decode detects", ""},
+	{Language, LanguageFull, "<language>", "letter frequencies to score the candidates with: english, polish or the path of a file of letter and frequency lines", DefaultLanguage},
+	{Top, TopFull, "<count>", "number of the best candidates to print", strconv.Itoa(DefaultTop)},
+	{DecodeBest, DecodeBestFull, "", "decode the input with the best candidate into the output file", ""},
+	{MaxKeyLength, MaxKeyLengthFull, "<length>", "length of the longest key to try when cracking the Vigenère cipher", strconv.Itoa(DefaultMaxKeyLength)},
+	{In, InFull, "<path>", "input file, - stands for the standard input", "-"},
+	{Out, OutFull, "<path>", "output file, - stands for the standard output", "-"},
+}
+
+// ParamFlagDefinitions returns the flags of the parameters of all the registered algorithms. A parameter
+// is set with the flag of its name, which is one of the FlagDefinitions or, for the parameters unknown
+// to them, a full flag without a short form.
+func ParamFlagDefinitions() []FlagDefinition {
+	var flagDefinitions []FlagDefinition
+	for _, algorithm := range cipher.Algorithms() {
+		for _, param := range algorithm.Params {
+			fullFlag := paramFlag(param.Name)
+			if _, ok := FindFlagDefinition(flagDefinitions, string(fullFlag)); ok {
+				continue
+			}
+			flagDefinition, ok := FindFlagDefinition(FlagDefinitions, string(fullFlag))
+			if !ok {
+				flagDefinition = FlagDefinition{"", fullFlag, "<" + param.Name + ">", param.Description, ""}
+			}
+			flagDefinitions = append(flagDefinitions, flagDefinition)
+		}
+	}
+	return flagDefinitions
+}
+
+// AllFlagDefinitions returns the FlagDefinitions along with the flags of the parameters unknown to them.
+func AllFlagDefinitions() []FlagDefinition {
+	flagDefinitions := slices.Clone(FlagDefinitions)
+	for _, flagDefinition := range ParamFlagDefinitions() {
+		if flagDefinition.Flag == "" {
+			flagDefinitions = append(flagDefinitions, flagDefinition)
+		}
+	}
+	return flagDefinitions
+}
+
+func paramFlag(paramName string) Flag {
+	return Flag("--" + paramName)
+}
+
+func paramName(fullFlag Flag) string {
+	return strings.TrimPrefix(string(fullFlag), "--")
+}
+
+func GetFlagDefinition(flag Flag) (FlagDefinition, bool) {
+	for _, flagDefinition := range FlagDefinitions {
+		if flagDefinition.Flag == flag {
+			return flagDefinition, true
+		}
+	}
+	return FlagDefinition{}, false
+}
+
+func AlgNames() []string {
+	algorithms := cipher.Algorithms()
+	names := make([]string, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		names = append(names, algorithm.Name)
+	}
+	return names
+}
+
+func IsHelpRequested(argMap map[string]string) bool {
+	_, ok := getOptionalFlagValue(argMap, Help, HelpFull)
+	return ok
+}
+
+// SelectFlagDefinitions returns the definitions of the given flags, in the given order.
+func SelectFlagDefinitions(flags ...Flag) []FlagDefinition {
+	flagDefinitions := make([]FlagDefinition, 0, len(flags))
+	for _, flag := range flags {
+		if flagDefinition, ok := GetFlagDefinition(flag); ok {
+			flagDefinitions = append(flagDefinitions, flagDefinition)
+		}
+	}
+	return flagDefinitions
+}
+
+func WriteFlagsHelp(writer io.Writer, flagDefinitions []FlagDefinition) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tabWriter, "Flags:")
+	for _, flagDefinition := range flagDefinitions {
+		writeFlagDefinition(tabWriter, flagDefinition)
+	}
+	return tabWriter.Flush()
+}
+
+func WriteAlgsHelp(writer io.Writer) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tabWriter, "Algorithms:")
+	for _, algorithm := range cipher.Algorithms() {
+		_, _ = fmt.Fprintf(tabWriter, "  %s\t%s\n", algorithm.Name, describeParams(algorithm))
+	}
+	return tabWriter.Flush()
+}
+
+func WriteAlgHelp(writer io.Writer, alg Alg) error {
+	algorithm, ok := cipher.Lookup(string(alg))
+	if !ok {
+		return &ErrUnknownAlgorithm{string(alg)}
+	}
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tabWriter, "Algorithm: %s\n  %s\n", algorithm.Name, algorithm.Description)
+	if len(algorithm.Aliases) != 0 {
+		_, _ = fmt.Fprintf(tabWriter, "\nAliases: %s\n", strings.Join(algorithm.Aliases, ", "))
+	}
+	_, _ = fmt.Fprintf(tabWriter, "\nUnits: %s\n", joinUnits(algorithm.Units))
+	if len(algorithm.Params) != 0 {
+		_, _ = fmt.Fprintln(tabWriter, "\nParameters:")
+	}
+	for _, param := range algorithm.Params {
+		flagDefinition, _ := FindFlagDefinition(ParamFlagDefinitions(), string(paramFlag(param.Name)))
+		_, _ = fmt.Fprintf(tabWriter, "  %s\t%s\n", formatFlags(flagDefinition), describeParam(param))
+	}
+	return tabWriter.Flush()
+}
+
+// WritePipelineHelp writes the help of every algorithm of the pipeline.
+func WritePipelineHelp(writer io.Writer, stages []Stage) error {
+	for i, stage := range stages {
+		if i != 0 {
+			_, _ = fmt.Fprintln(writer)
+		}
+		if err := WriteAlgHelp(writer, stage.Alg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFlagDefinition(writer io.Writer, flagDefinition FlagDefinition) {
+	description := flagDefinition.Description
+	if flagDefinition.Default != "" {
+		description += fmt.Sprintf(" (default: %s)", flagDefinition.Default)
+	}
+	_, _ = fmt.Fprintf(writer, "  %s\t%s\n", formatFlags(flagDefinition), description)
+}
+
+func formatFlags(flagDefinition FlagDefinition) string {
+	flags := fmt.Sprintf("%s, %s", flagDefinition.Flag, flagDefinition.FullFlag)
+	if flagDefinition.Flag == "" {
+		flags = fmt.Sprintf("    %s", flagDefinition.FullFlag)
+	}
+	if flagDefinition.Value != "" {
+		flags += "=" + flagDefinition.Value
+	}
+	return flags
+}
+
+func describeParam(param cipher.Param) string {
+	description := fmt.Sprintf("%s, %s", param.Type, param.Description)
+	if param.Type == cipher.IntType && (param.Min != 0 || param.Max != 0) {
+		description += fmt.Sprintf(" within %d-%d", param.Min, param.Max)
+	}
+	switch {
+	case param.Required:
+		return description + ", required"
+	case param.Default != "":
+		return description + fmt.Sprintf(", optional (default: %s)", param.Default)
+	default:
+		return description + ", optional"
+	}
+}
+
+func describeParams(algorithm cipher.Algorithm) string {
+	params := make([]string, 0, len(algorithm.Params)+1)
+	for _, param := range algorithm.Params {
+		flagDefinition, _ := FindFlagDefinition(ParamFlagDefinitions(), string(paramFlag(param.Name)))
+		if param.Required {
+			params = append(params, fmt.Sprintf("%s=%s", flagDefinition.FullFlag, flagDefinition.Value))
+		} else {
+			params = append(params, fmt.Sprintf("[%s=%s]", flagDefinition.FullFlag, flagDefinition.Value))
+		}
+	}
+	params = append(params, "units: "+joinUnits(algorithm.Units))
+	return strings.Join(params, " ")
+}
+
+func joinUnits(units []cipher.Unit) string {
+	unitNames := make([]string, 0, len(units))
+	for _, unit := range units {
+		unitNames = append(unitNames, string(unit))
+	}
+	return strings.Join(unitNames, ", ")
+}
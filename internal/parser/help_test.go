@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_WriteFlagsHelp(t *testing.T) {
+	// given
+	output := new(bytes.Buffer)
+	// when
+	err := WriteFlagsHelp(output, FlagDefinitions)
+	// then
+	assert.NoError(t, err)
+	for _, flagDefinition := range FlagDefinitions {
+		assert.Contains(t, output.String(), string(flagDefinition.Flag))
+		assert.Contains(t, output.String(), string(flagDefinition.FullFlag))
+		assert.Contains(t, output.String(), flagDefinition.Description)
+	}
+}
+
+func Test_WriteAlgsHelp(t *testing.T) {
+	// given
+	output := new(bytes.Buffer)
+	// when
+	err := WriteAlgsHelp(output)
+	// then
+	assert.NoError(t, err)
+	for _, algName := range AlgNames() {
+		assert.Contains(t, output.String(), algName)
+	}
+}
+
+func Test_SelectFlagDefinitions(t *testing.T) {
+	// given
+	expectedFlags := []Flag{Out, Key}
+	// when
+	result := SelectFlagDefinitions(Out, Key)
+	// then
+	assert.Len(t, result, len(expectedFlags))
+	for i, flagDefinition := range result {
+		assert.Equal(t, expectedFlags[i], flagDefinition.Flag)
+	}
+}
+
+func Test_WriteAlgHelp(t *testing.T) {
+	// given
+	output := new(bytes.Buffer)
+	// when
+	err := WriteAlgHelp(output, "caesar")
+	// then
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), "Algorithm: caesar")
+	assert.Contains(t, output.String(), "--key=<key>")
+	assert.Contains(t, output.String(), "--alphabet=<alphabet>")
+}
+
+func Test_WriteAlgHelp_unknownAlgorithm(t *testing.T) {
+	// given
+	output := new(bytes.Buffer)
+	expectedErr := &ErrUnknownAlgorithm{"rot"}
+	// when
+	err := WriteAlgHelp(output, "rot")
+	// then
+	assert.Equal(t, expectedErr, err)
+}
@@ -2,33 +2,151 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
+// EndOfOptions makes all the following arguments positional, even if they start with a hyphen.
+const EndOfOptions = "--"
+
+// Parse accepts the flags in the forms: -flag=value, -flag value, --flag=value and --flag value, where
+// only the flags of the AllFlagDefinitions with a value take the following argument as their value. The
+// positional arguments stand for the input and the output path, in this order.
 func Parse(args []string) (map[string]string, error) {
+	return ParseFlags(args, AllFlagDefinitions())
+}
+
+// ParseFlags works like Parse, but for the given subset of the FlagDefinitions. The positional arguments
+// are accepted only for the input and the output flags present in the subset, and fill only the ones
+// left unset by the flags.
+func ParseFlags(args []string, flagDefinitions []FlagDefinition) (map[string]string, error) {
 	argMap := make(map[string]string)
-	for position, arg := range args {
-		if err := parseArg(argMap, position, arg); err != nil {
-			return nil, err
+	seenFlags := make(map[string]bool)
+	var positionalArgs []positionalArg
+	optionsEnded := false
+	for position := 0; position < len(args); position++ {
+		arg := args[position]
+		switch {
+		case !optionsEnded && arg == EndOfOptions:
+			optionsEnded = true
+		case !optionsEnded && isValidArg(arg):
+			flag, value, isPairArg := parsePairArg(arg)
+			if !isPairArg {
+				flag = arg
+				if takesValue(flagDefinitions, flag) {
+					if position+1 == len(args) {
+						return nil, &ErrMissingValue{flag, position}
+					}
+					position++
+					value = args[position]
+				}
+			}
+			if err := setFlag(argMap, seenFlags, flagDefinitions, flag, value, position); err != nil {
+				return nil, err
+			}
+		default:
+			positionalArgs = append(positionalArgs, positionalArg{arg, position})
 		}
 	}
+	if err := setPositionalFlags(argMap, seenFlags, flagDefinitions, positionalArgs); err != nil {
+		return nil, err
+	}
 	return argMap, nil
 }
 
-func parseArg(argMap map[string]string, position int, arg string) error {
-	if !isValidArg(arg) {
-		return &ErrInvalidArg{arg, position}
+type positionalArg struct {
+	arg      string
+	position int
+}
+
+// setPositionalFlags assigns the positional arguments, in their order, to the positional flags left unset.
+func setPositionalFlags(
+	argMap map[string]string,
+	seenFlags map[string]bool,
+	flagDefinitions []FlagDefinition,
+	positionalArgs []positionalArg,
+) error {
+	positionalFlags := filterPositionalFlags(flagDefinitions)
+	for _, positionalArg := range positionalArgs {
+		positionalFlags = slices.DeleteFunc(positionalFlags, func(positionalFlag Flag) bool {
+			flagDefinition, _ := FindFlagDefinition(flagDefinitions, string(positionalFlag))
+			return seenFlags[string(flagDefinition.FullFlag)]
+		})
+		if len(positionalFlags) == 0 {
+			return &ErrInvalidArg{positionalArg.arg, positionalArg.position}
+		}
+		err := setFlag(argMap, seenFlags, flagDefinitions, string(positionalFlags[0]), positionalArg.arg, positionalArg.position)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func filterPositionalFlags(flagDefinitions []FlagDefinition) []Flag {
+	positionalFlags := make([]Flag, 0, 2)
+	for _, positionalFlag := range []Flag{In, Out} {
+		if _, ok := FindFlagDefinition(flagDefinitions, string(positionalFlag)); ok {
+			positionalFlags = append(positionalFlags, positionalFlag)
+		}
+	}
+	return positionalFlags
+}
+
+// setFlag detects the duplicates by the full form of the flag, so that the short and the full form
+// of the same flag are duplicates too. The repeated algorithm flags are not duplicates, but the
+// consecutive stages of a pipeline.
+func setFlag(
+	argMap map[string]string,
+	seenFlags map[string]bool,
+	flagDefinitions []FlagDefinition,
+	flag string,
+	value string,
+	position int,
+) error {
+	canonicalFlag := flag
+	if flagDefinition, ok := FindFlagDefinition(flagDefinitions, flag); ok {
+		canonicalFlag = string(flagDefinition.FullFlag)
+	}
+	if seenFlags[canonicalFlag] && canonicalFlag == string(ChosenAlgFull) {
+		appendStage(argMap, value)
+		return nil
 	}
-	flag, value, isPairArg := parsePairArg(arg)
-	if !isPairArg {
-		flag = arg
+	if seenFlags[canonicalFlag] {
+		return &ErrDuplicateFlag{flag, position}
 	}
+	seenFlags[canonicalFlag] = true
 	argMap[flag] = value
 	return nil
 }
 
+func appendStage(argMap map[string]string, value string) {
+	for _, flag := range []Flag{ChosenAlg, ChosenAlgFull} {
+		if pipeline, ok := argMap[string(flag)]; ok {
+			argMap[string(flag)] = pipeline + StageSeparator + value
+			return
+		}
+	}
+}
+
+func takesValue(flagDefinitions []FlagDefinition, flag string) bool {
+	flagDefinition, ok := FindFlagDefinition(flagDefinitions, flag)
+	return ok && flagDefinition.Value != ""
+}
+
+// FindFlagDefinition finds the definition of the flag given in either the short or the full form.
+func FindFlagDefinition(flagDefinitions []FlagDefinition, flag string) (FlagDefinition, bool) {
+	for _, flagDefinition := range flagDefinitions {
+		if string(flagDefinition.Flag) == flag || string(flagDefinition.FullFlag) == flag {
+			return flagDefinition, true
+		}
+	}
+	return FlagDefinition{}, false
+}
+
+// A lone hyphen is not a flag, but the standard stream path.
 func isValidArg(arg string) bool {
-	return strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--")
+	return len(arg) > 1 && (strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--"))
 }
 
 func parsePairArg(arg string) (string, string, bool) {
@@ -47,3 +165,21 @@ type ErrInvalidArg struct {
 func (e *ErrInvalidArg) Error() string {
 	return fmt.Sprintf("invalid argument: %s at: %d", e.arg, e.position)
 }
+
+type ErrMissingValue struct {
+	Flag     string
+	Position int
+}
+
+func (e *ErrMissingValue) Error() string {
+	return fmt.Sprintf("missing value of flag: %s at: %d", e.Flag, e.Position)
+}
+
+type ErrDuplicateFlag struct {
+	Flag     string
+	Position int
+}
+
+func (e *ErrDuplicateFlag) Error() string {
+	return fmt.Sprintf("duplicate flag: %s at: %d", e.Flag, e.Position)
+}
@@ -43,11 +43,12 @@ func Test_parse_IncorrectArg(t *testing.T) {
 	// given
 	input := []string{
 		"-arg",
-		"-one=two",
+		"in.txt",
+		"out.txt",
 		"wrong",
 		"--five",
 	}
-	var expectedErr error = &ErrInvalidArg{"wrong", 2}
+	var expectedErr error = &ErrInvalidArg{"wrong", 3}
 	var expectedMap map[string]string = nil
 	// when
 	resultMap, resultErr := Parse(input)
@@ -113,3 +114,143 @@ func Test_parrsePairArg_NoPairArg(t *testing.T) {
 	assert.Equal(t, expectedValue, resultValue)
 	assert.Equal(t, expectedIsPair, resultIsPair)
 }
+
+func Test_parse_spaceSeparatedValues(t *testing.T) {
+	// given
+	input := []string{
+		"-m", "encode",
+		"--algorithm", "caesar",
+		"-k", "-3",
+		"--input=in.txt",
+		"-o", "-",
+		"-h",
+	}
+	var expectedErr error = nil
+	expectedMap := map[string]string{
+		"-m":          "encode",
+		"--algorithm": "caesar",
+		"-k":          "-3",
+		"--input":     "in.txt",
+		"-o":          "-",
+		"-h":          "",
+	}
+	// when
+	resultMap, resultErr := Parse(input)
+	// then
+	assert.Equal(t, expectedErr, resultErr)
+	assert.Equal(t, expectedMap, resultMap)
+}
+
+func Test_parse_positionalPaths(t *testing.T) {
+	// given
+	input := []string{
+		"-a=mirror",
+		"in.txt",
+		"--",
+		"-out.txt",
+	}
+	var expectedErr error = nil
+	expectedMap := map[string]string{
+		"-a": "mirror",
+		"-i": "in.txt",
+		"-o": "-out.txt",
+	}
+	// when
+	resultMap, resultErr := Parse(input)
+	// then
+	assert.Equal(t, expectedErr, resultErr)
+	assert.Equal(t, expectedMap, resultMap)
+}
+
+func Test_parse_positionalPathsAfterFlags(t *testing.T) {
+	// given
+	inputs := [][]string{
+		{"-i", "in.txt", "out.txt"},
+		{"out.txt", "--input=in.txt"},
+		{"-o=out.txt", "in.txt"},
+	}
+	expectedMaps := []map[string]string{
+		{"-i": "in.txt", "-o": "out.txt"},
+		{"--input": "in.txt", "-o": "out.txt"},
+		{"-i": "in.txt", "-o": "out.txt"},
+	}
+	for i, input := range inputs {
+		// when
+		resultMap, resultErr := Parse(input)
+		// then
+		assert.NoError(t, resultErr, input)
+		assert.Equal(t, expectedMaps[i], resultMap, input)
+	}
+}
+
+func Test_parse_excessPositionalPaths(t *testing.T) {
+	// given
+	inputs := [][]string{
+		{"-o=a.txt", "in.txt", "out.txt"},
+		{"-i=in.txt", "-o=out.txt", "extra.txt"},
+	}
+	expectedErrs := []error{
+		&ErrInvalidArg{"out.txt", 2},
+		&ErrInvalidArg{"extra.txt", 2},
+	}
+	for i, input := range inputs {
+		// when
+		resultMap, resultErr := Parse(input)
+		// then
+		assert.Equal(t, expectedErrs[i], resultErr, input)
+		assert.Nil(t, resultMap)
+	}
+}
+
+func Test_parse_duplicateFlag(t *testing.T) {
+	// given
+	inputs := [][]string{
+		{"--input=a.txt", "-i", "b.txt"},
+		{"-k", "1", "-k=2"},
+	}
+	expectedErrs := []error{
+		&ErrDuplicateFlag{"-i", 2},
+		&ErrDuplicateFlag{"-k", 2},
+	}
+	for i, input := range inputs {
+		// when
+		resultMap, resultErr := Parse(input)
+		// then
+		assert.Equal(t, expectedErrs[i], resultErr)
+		assert.Nil(t, resultMap)
+	}
+}
+
+func Test_parse_repeatedAlgorithm(t *testing.T) {
+	// given
+	input := []string{"-a", "caesar:3", "--algorithm=mirror", "-a", "vigenere:key"}
+	expectedMap := map[string]string{
+		"-a": "caesar:3,mirror,vigenere:key",
+	}
+	// when
+	resultMap, resultErr := Parse(input)
+	// then
+	assert.NoError(t, resultErr)
+	assert.Equal(t, expectedMap, resultMap)
+}
+
+func Test_parse_missingValue(t *testing.T) {
+	// given
+	input := []string{"-m=encode", "--key"}
+	var expectedErr error = &ErrMissingValue{"--key", 1}
+	// when
+	resultMap, resultErr := Parse(input)
+	// then
+	assert.Equal(t, expectedErr, resultErr)
+	assert.Nil(t, resultMap)
+}
+
+func Test_isValidArg_LoneHyphen(t *testing.T) {
+	// given
+	input := "-"
+	expected := false
+	// when
+	result := isValidArg(input)
+	// then
+	assert.Equal(t, expected, result)
+}
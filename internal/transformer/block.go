@@ -0,0 +1,77 @@
+package transformer
+
+import (
+	"bufio"
+	"io"
+)
+
+// DefaultBlockSize is the number of runes of the blocks transformed as a whole, which bounds the memory of
+// the block transfer whatever the input size.
+const DefaultBlockSize = 64 * 1024
+
+// ApplyBlockFuncAndTransfer reads the UTF-8 encoded runes from the reader until io.EOF in blocks of the
+// block size, the last one being shorter unless the input fills it, and writes every block transformed as
+// a whole to the writer. Unlike the per-rune transform functions, the block one can reorder the runes,
+// e.g. to transpose them. The invalid bytes are handled according to the policy, except for the
+// InvalidPassthrough, which would leave them out of place, so they are reported just like with InvalidError.
+// The reads and the writes are buffered with the sizes given by the read buffer size, like with the
+// PooledApplyPositionalFuncAndTransfer.
+func ApplyBlockFuncAndTransfer(
+	reader io.Reader,
+	writer io.Writer,
+	readBufferSize int,
+	blockSize int,
+	transformFunc func(output []rune, block []rune) []rune,
+	policy InvalidPolicy,
+) (err error) {
+	readBufferSize = min(max(readBufferSize, MinBufferSize), MaxBufferSize)
+	bufferedReader := bufio.NewReaderSize(reader, readBufferSize)
+	bufferedWriter := bufio.NewWriterSize(writer, writeBufferSize(readBufferSize))
+	defer func() {
+		if flushErr := bufferedWriter.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+	// The blocks grow up to the block size only if the input is long enough.
+	block := make([]rune, 0, min(blockSize, readBufferSize))
+	var output []rune
+	var offset int64
+	for {
+		r, size, readErr := bufferedReader.ReadRune()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+		switch {
+		case !isInvalidRune(r, size) || policy == InvalidReplace:
+			block = append(block, r)
+		case policy != InvalidSkip:
+			_ = bufferedReader.UnreadRune()
+			invalidInput, _ := bufferedReader.Peek(maxReportedInvalidBytes)
+			return &ErrInvalidUTF8{offset, invalidBytes(invalidInput)}
+		}
+		offset += int64(size)
+		if len(block) == blockSize {
+			output = transformFunc(output[:0], block)
+			if err = writeBlock(bufferedWriter, output); err != nil {
+				return err
+			}
+			block = block[:0]
+		}
+	}
+	if len(block) == 0 {
+		return nil
+	}
+	return writeBlock(bufferedWriter, transformFunc(output[:0], block))
+}
+
+func writeBlock(writer *bufio.Writer, block []rune) error {
+	for _, r := range block {
+		if _, err := writer.WriteRune(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package transformer
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// reverseBlockFunc reverses the order of the runes of the block.
+func reverseBlockFunc(output []rune, block []rune) []rune {
+	start := len(output)
+	output = append(output, block...)
+	slices.Reverse(output[start:])
+	return output
+}
+
+func Test_ApplyBlockFuncAndTransfer(t *testing.T) {
+	// given
+	inputs := []string{"", "abc", "abcdef", "Zażółć gęślą jaźń ✈ 𝄞"}
+	expected := []string{"", "cba", "cbafed", "żaZćłóęg ąlśaj  ńź𝄞 ✈"}
+	for i, input := range inputs {
+		output := new(bytes.Buffer)
+		// when
+		err := ApplyBlockFuncAndTransfer(iotest.OneByteReader(strings.NewReader(input)), output, ReadBufferSize, 3, reverseBlockFunc, InvalidError)
+		// then
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected[i], output.String(), input)
+	}
+}
+
+func Test_ApplyBlockFuncAndTransfer_largeInput(t *testing.T) {
+	// given
+	input := strings.Repeat(streamInput, 10_000)
+	encoded := new(bytes.Buffer)
+	decoded := new(bytes.Buffer)
+	// when
+	encodeErr := ApplyBlockFuncAndTransfer(strings.NewReader(input), encoded, ReadBufferSize, DefaultBlockSize, reverseBlockFunc, InvalidError)
+	decodeErr := ApplyBlockFuncAndTransfer(encoded, decoded, ReadBufferSize, DefaultBlockSize, reverseBlockFunc, InvalidError)
+	// then
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	assert.True(t, input == decoded.String())
+}
+
+// readSizeRecorder records the largest read the reader has been asked for.
+type readSizeRecorder struct {
+	reader  *strings.Reader
+	maxRead int
+}
+
+func (recorder *readSizeRecorder) Read(p []byte) (int, error) {
+	recorder.maxRead = max(recorder.maxRead, len(p))
+	return recorder.reader.Read(p)
+}
+
+func Test_ApplyBlockFuncAndTransfer_readBufferSize(t *testing.T) {
+	// given
+	input := strings.Repeat(streamInput, 100)
+	for _, readBufferSize := range []int{64, ReadBufferSize, 64 * 1024} {
+		reader := &readSizeRecorder{reader: strings.NewReader(input)}
+		output := new(bytes.Buffer)
+		// when
+		err := ApplyBlockFuncAndTransfer(reader, output, readBufferSize, 3, reverseBlockFunc, InvalidError)
+		// then
+		assert.NoError(t, err, readBufferSize)
+		assert.Equal(t, readBufferSize, reader.maxRead, readBufferSize)
+	}
+}
+
+func Test_ApplyBlockFuncAndTransfer_invalidPolicies(t *testing.T) {
+	// given
+	input := "abcd\xFFef\xE2\x9C"
+	policies := []InvalidPolicy{InvalidError, InvalidReplace, InvalidSkip, InvalidPassthrough}
+	expectedOutputs := []string{"cba", "cbae\uFFFDd\uFFFD\uFFFDf", "cbafed", "cba"}
+	expectedErrs := []error{
+		&ErrInvalidUTF8{4, []byte{0xFF}},
+		nil,
+		nil,
+		&ErrInvalidUTF8{4, []byte{0xFF}},
+	}
+	for i, policy := range policies {
+		output := new(bytes.Buffer)
+		// when
+		err := ApplyBlockFuncAndTransfer(strings.NewReader(input), output, ReadBufferSize, 3, reverseBlockFunc, policy)
+		// then
+		assert.Equal(t, expectedErrs[i], err, policy)
+		assert.Equal(t, expectedOutputs[i], output.String(), policy)
+	}
+}
+
+func Test_ApplyBlockFuncAndTransfer_errors(t *testing.T) {
+	// given
+	writeErr := errors.New("disk full")
+	// when
+	readResultErr := ApplyBlockFuncAndTransfer(iotest.ErrReader(iotest.ErrTimeout), new(bytes.Buffer), ReadBufferSize, 3, reverseBlockFunc, InvalidError)
+	writeResultErr := ApplyBlockFuncAndTransfer(strings.NewReader(parallelTestInput), &failingWriter{writeErr}, ReadBufferSize, 3, reverseBlockFunc, InvalidError)
+	// then
+	assert.Equal(t, iotest.ErrTimeout, readResultErr)
+	assert.Equal(t, writeErr, writeResultErr)
+}
@@ -0,0 +1,45 @@
+package transformer
+
+import (
+	"errors"
+	"io"
+)
+
+// FilesApplyByteFuncAndTransfer transforms the file byte by byte, so unlike FilesApplyFuncAndTransfer,
+// it accepts any file, not only the valid UTF-8 ones.
+func FilesApplyByteFuncAndTransfer(
+	inputFilePath string,
+	outputFilePath string,
+	buffer []byte,
+	transformFunc func(b byte) byte,
+) error {
+	return FilesTransfer(inputFilePath, outputFilePath, func(reader io.Reader, writer io.Writer) error {
+		return ApplyByteFuncAndTransfer(reader, writer, buffer, transformFunc)
+	})
+}
+
+// ApplyByteFuncAndTransfer is the io.Reader and io.Writer counterpart of FilesApplyByteFuncAndTransfer.
+// Each byte maps to exactly one byte, so the buffer is transformed in place and no write buffer is needed.
+func ApplyByteFuncAndTransfer(
+	reader io.Reader,
+	writer io.Writer,
+	buffer []byte,
+	transformFunc func(b byte) byte,
+) error {
+	for {
+		readSize, err := reader.Read(buffer)
+		chunk := buffer[:readSize]
+		for i, b := range chunk {
+			chunk[i] = transformFunc(b)
+		}
+		if _, writeErr := writer.Write(chunk); writeErr != nil {
+			return writeErr
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package transformer
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/iotest"
+)
+
+var xorByteFunc = func(b byte) byte {
+	return b ^ 0x5A
+}
+
+func Test_applyByteFuncAndTransfer_invalidUTF8(t *testing.T) {
+	// given
+	input := []byte{0xFF, 0xFE, 0x00, 'a', inputRuneBytes[0], inputRuneBytes[1]}
+	reader := bytes.NewReader(input)
+	writer := new(bytes.Buffer)
+	expected := make([]byte, len(input))
+	for i, b := range input {
+		expected[i] = xorByteFunc(b)
+	}
+	buffer := make([]byte, 4)
+	// when
+	err := ApplyByteFuncAndTransfer(reader, writer, buffer, xorByteFunc)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, writer.Bytes())
+}
+
+func Test_applyByteFuncAndTransfer_roundTrip(t *testing.T) {
+	// given
+	input := make([]byte, 10*1024+3)
+	for i := range input {
+		input[i] = byte(i * 7)
+	}
+	encoded := new(bytes.Buffer)
+	decoded := new(bytes.Buffer)
+	// when
+	encodeErr := ApplyByteFuncAndTransfer(iotest.HalfReader(bytes.NewReader(input)), encoded, make([]byte, ReadBufferSize), xorByteFunc)
+	decodeErr := ApplyByteFuncAndTransfer(iotest.DataErrReader(encoded), decoded, make([]byte, ReadBufferSize), xorByteFunc)
+	// then
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	assert.Equal(t, input, decoded.Bytes())
+}
+
+func Test_applyByteFuncAndTransfer_readError(t *testing.T) {
+	// given
+	reader := iotest.ErrReader(iotest.ErrTimeout)
+	writer := new(bytes.Buffer)
+	// when
+	err := ApplyByteFuncAndTransfer(reader, writer, make([]byte, 4), xorByteFunc)
+	// then
+	assert.Equal(t, iotest.ErrTimeout, err)
+	assert.Equal(t, 0, writer.Len())
+}
@@ -0,0 +1,112 @@
+package transformer
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// InvalidPolicy tells what to do with the bytes of the input, which are not valid UTF-8.
+type InvalidPolicy string
+
+const (
+	InvalidError       InvalidPolicy = "error"       // Stop with ErrInvalidUTF8.
+	InvalidReplace     InvalidPolicy = "replace"     // Transform each invalid byte as if it were U+FFFD.
+	InvalidSkip        InvalidPolicy = "skip"        // Leave the invalid bytes out of the output.
+	InvalidPassthrough InvalidPolicy = "passthrough" // Copy the invalid bytes to the output untransformed.
+)
+
+// InvalidPolicies lists all the policies, the first one being the default.
+var InvalidPolicies = []InvalidPolicy{InvalidError, InvalidReplace, InvalidSkip, InvalidPassthrough}
+
+// maxReportedInvalidBytes limits the invalid bytes reported by ErrInvalidUTF8, as the input may well be binary.
+const maxReportedInvalidBytes = 16
+
+// runeTransformer transforms the runes split over the consecutive chunks of the input, counting the
+// offset of the input bytes, so that the invalid ones can be located in the whole input.
+type runeTransformer struct {
+	transformFunc func(r rune) rune
+	policy        InvalidPolicy
+	offset        int64
+}
+
+func newRuneTransformer(transformFunc func(r rune) rune, policy InvalidPolicy) *runeTransformer {
+	if policy == "" {
+		policy = InvalidError
+	}
+	return &runeTransformer{transformFunc: transformFunc, policy: policy}
+}
+
+// transform appends the transformed runes of the input to the output. It returns the number of input bytes
+// consumed, which excludes the trailing bytes of an incomplete rune, unless the input is at its end.
+// The transform function rejects a rune by returning an invalid one, which stops with ErrUntransformableRune.
+func (transformer *runeTransformer) transform(output []byte, input []byte, atEOF bool) ([]byte, int, error) {
+	if isASCII(input) {
+		output, consumed := appendTransformedASCII(output, input, transformer.transformFunc)
+		transformer.offset += int64(consumed)
+		if consumed < len(input) {
+			return output, consumed, &ErrUntransformableRune{transformer.offset, rune(input[consumed])}
+		}
+		return output, consumed, nil
+	}
+	consumed := 0
+	for consumed < len(input) && (atEOF || utf8.FullRune(input[consumed:])) {
+		inputRune, inputRuneSize := utf8.DecodeRune(input[consumed:])
+		if isInvalidRune(inputRune, inputRuneSize) && transformer.policy != InvalidReplace {
+			switch transformer.policy {
+			case InvalidPassthrough:
+				output = append(output, input[consumed])
+			case InvalidSkip:
+			default:
+				return output, consumed, &ErrInvalidUTF8{transformer.offset, invalidBytes(input[consumed:])}
+			}
+		} else {
+			// The invalid byte decodes as U+FFFD, its replacement.
+			transformedRune := transformer.transformFunc(inputRune)
+			if !utf8.ValidRune(transformedRune) {
+				return output, consumed, &ErrUntransformableRune{transformer.offset, inputRune}
+			}
+			output = utf8.AppendRune(output, transformedRune)
+		}
+		consumed += inputRuneSize
+		transformer.offset += int64(inputRuneSize)
+	}
+	return output, consumed, nil
+}
+
+// invalidBytes copies the invalid bytes the input starts with, up to the start of the next valid rune,
+// or of the rune which may yet be completed by the following input.
+func invalidBytes(input []byte) []byte {
+	size := 1
+	for size < min(len(input), maxReportedInvalidBytes) && utf8.FullRune(input[size:]) {
+		if inputRune, inputRuneSize := utf8.DecodeRune(input[size:]); !isInvalidRune(inputRune, inputRuneSize) {
+			break
+		}
+		size++
+	}
+	return append([]byte(nil), input[:size]...)
+}
+
+func isInvalidRune(r rune, size int) bool {
+	return r == utf8.RuneError && size == 1
+}
+
+// ErrInvalidUTF8 locates the invalid bytes in the input, the Offset counting the bytes preceding them.
+type ErrInvalidUTF8 struct {
+	Offset int64
+	Bytes  []byte
+}
+
+func (e *ErrInvalidUTF8) Error() string {
+	return fmt.Sprintf("invalid UTF-8 at byte offset: %d, bytes: % x", e.Offset, e.Bytes)
+}
+
+// ErrUntransformableRune locates the rune of the input, which the transform function has rejected by
+// returning an invalid rune, e.g. because the rune is outside of the domain of the cipher.
+type ErrUntransformableRune struct {
+	Offset int64
+	Rune   rune
+}
+
+func (e *ErrUntransformableRune) Error() string {
+	return fmt.Sprintf("rune: %q (%U) at byte offset: %d cannot be transformed by the cipher", e.Rune, e.Rune, e.Offset)
+}
@@ -0,0 +1,93 @@
+package transformer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func Test_runeTransformer_policies(t *testing.T) {
+	// given
+	input := []byte("a\xFFb")
+	a, b := string(transformFunc('a')), string(transformFunc('b'))
+	policies := []InvalidPolicy{InvalidError, InvalidReplace, InvalidSkip, InvalidPassthrough, ""}
+	expectedOutputs := []string{a, a + string(transformFunc(utf8.RuneError)) + b, a + b, a + "\xFF" + b, a}
+	expectedConsumed := []int{1, 3, 3, 3, 1}
+	expectedErrs := []error{&ErrInvalidUTF8{1, []byte{0xFF}}, nil, nil, nil, &ErrInvalidUTF8{1, []byte{0xFF}}}
+	for i, policy := range policies {
+		// when
+		output, consumed, err := newRuneTransformer(transformFunc, policy).transform(nil, input, true)
+		// then
+		assert.Equal(t, expectedOutputs[i], string(output), policy)
+		assert.Equal(t, expectedConsumed[i], consumed, policy)
+		assert.Equal(t, expectedErrs[i], err, policy)
+	}
+}
+
+func Test_runeTransformer_offsetKeptAcrossChunks(t *testing.T) {
+	// given
+	transformer := newRuneTransformer(transformFunc, InvalidError)
+	_, consumed, err := transformer.transform(nil, append([]byte("ab"), inputRuneBytes[:2]...), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, consumed)
+	// when
+	_, _, err = transformer.transform(nil, append(inputRuneBytes, 0x80), false)
+	// then
+	assert.Equal(t, &ErrInvalidUTF8{int64(2 + len(inputRuneBytes)), []byte{0x80}}, err)
+}
+
+func Test_runeTransformer_untransformableRune(t *testing.T) {
+	// given
+	rejectingFunc := func(r rune) rune {
+		if r == 'x' || r == 'ł' {
+			return -1
+		}
+		return transformFunc(r)
+	}
+	inputs := []string{"abxc", "żbxc", "żbłc"}
+	expectedOutputs := []string{"ab", "żb", "żb"}
+	expectedErrs := []error{
+		&ErrUntransformableRune{2, 'x'},
+		&ErrUntransformableRune{3, 'x'},
+		&ErrUntransformableRune{3, 'ł'},
+	}
+	for i, input := range inputs {
+		// when
+		output, consumed, err := newRuneTransformer(rejectingFunc, InvalidError).transform(nil, []byte(input), true)
+		// then
+		assert.Equal(t, transformString(expectedOutputs[i], transformFunc), string(output), input)
+		assert.Equal(t, len(expectedOutputs[i]), consumed, input)
+		assert.Equal(t, expectedErrs[i], err, input)
+	}
+}
+
+func Test_invalidBytes(t *testing.T) {
+	// given
+	inputs := []string{"\xFFa", "\xFF\xFE\x80", "\xE2\xE2\x9C", "\xF0\x9F", strings.Repeat("\xFF", maxReportedInvalidBytes+1)}
+	expectedSizes := []int{1, 3, 1, 2, maxReportedInvalidBytes}
+	for i, input := range inputs {
+		// when
+		result := invalidBytes([]byte(input))
+		// then
+		assert.Equal(t, []byte(input[:expectedSizes[i]]), result, input)
+	}
+}
+
+func Test_ErrInvalidUTF8_Error(t *testing.T) {
+	// given
+	err := &ErrInvalidUTF8{Offset: 42, Bytes: []byte{0xC3, 0x28}}
+	// when
+	message := err.Error()
+	// then
+	assert.Equal(t, "invalid UTF-8 at byte offset: 42, bytes: c3 28", message)
+}
+
+func Test_ErrUntransformableRune_Error(t *testing.T) {
+	// given
+	err := &ErrUntransformableRune{Offset: 5, Rune: 'ł'}
+	// when
+	message := err.Error()
+	// then
+	assert.Equal(t, "rune: 'ł' (U+0142) at byte offset: 5 cannot be transformed by the cipher", message)
+}
@@ -0,0 +1,200 @@
+package transformer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// ParallelChunkSize is the size of the chunks the input is split into for the workers, large enough for
+// handing them over between the goroutines to cost next to nothing compared to transforming them.
+const ParallelChunkSize = 1024 * 1024
+
+// ApplyPositionalFuncInParallel works like PooledApplyPositionalFuncAndTransfer, but the input is split at
+// the rune boundaries into chunks transformed concurrently by the workers and written in the input order.
+// The positions are counted by the splitting, so the transform function has to be safe for concurrent use.
+func ApplyPositionalFuncInParallel(
+	reader io.Reader,
+	writer io.Writer,
+	transformFunc func(position int, r rune) rune,
+	policy InvalidPolicy,
+	workers int,
+) error {
+	positionCounter := func(input []byte) int {
+		return countPositions(input, policy)
+	}
+	return transferInParallel(reader, writer, workers, positionCounter, func(chunk *parallelChunk) {
+		chunk.outputBuffer = getBuffer(writeBufferSize(ParallelChunkSize))
+		transformer := newRuneTransformer(withPositionFrom(chunk.position, transformFunc), policy)
+		transformer.offset = chunk.offset
+		// The chunks end at the rune boundaries, so their trailing incomplete runes are invalid.
+		chunk.output, _, chunk.err = transformer.transform(chunk.outputBuffer.AvailableBuffer(), chunk.input, true)
+	})
+}
+
+// countPositions counts the runes the transform function is called with, which include the invalid bytes
+// replaced with U+FFFD, but not those skipped or passed through.
+func countPositions(input []byte, policy InvalidPolicy) int {
+	if policy == InvalidReplace || utf8.Valid(input) {
+		return utf8.RuneCount(input)
+	}
+	positions := 0
+	for i := 0; i < len(input); {
+		inputRune, inputRuneSize := utf8.DecodeRune(input[i:])
+		if !isInvalidRune(inputRune, inputRuneSize) {
+			positions++
+		}
+		i += inputRuneSize
+	}
+	return positions
+}
+
+// ApplyByteFuncInParallel is the byte by byte counterpart of ApplyPositionalFuncInParallel, the chunks are
+// transformed in place.
+func ApplyByteFuncInParallel(reader io.Reader, writer io.Writer, transformFunc func(b byte) byte, workers int) error {
+	return transferInParallel(reader, writer, workers, nil, func(chunk *parallelChunk) {
+		for i, b := range chunk.input {
+			chunk.input[i] = transformFunc(b)
+		}
+		chunk.output = chunk.input
+	})
+}
+
+// parallelChunk holds the pooled buffers of its input and output until it has been written.
+type parallelChunk struct {
+	inputBuffer  *bytes.Buffer
+	input        []byte
+	offset       int64
+	position     int
+	outputBuffer *bytes.Buffer
+	output       []byte
+	err          error
+	done         chan struct{}
+}
+
+func (chunk *parallelChunk) release() {
+	putBuffer(ParallelChunkSize, chunk.inputBuffer)
+	if chunk.outputBuffer != nil {
+		putBuffer(writeBufferSize(ParallelChunkSize), chunk.outputBuffer)
+	}
+}
+
+// transferInParallel reads the chunks in one goroutine, hands them over to the workers, and writes them in
+// the current one as they are done, in the input order. At most twice as many chunks as there are workers
+// are held at a time, so the memory use does not depend on the size of the input. The chunks are split at
+// the rune boundaries when the positions of their runes are counted.
+func transferInParallel(
+	reader io.Reader,
+	writer io.Writer,
+	workers int,
+	countPositions func(input []byte) int,
+	transform func(chunk *parallelChunk),
+) error {
+	workers = max(1, workers)
+	jobs := make(chan *parallelChunk)
+	pending := make(chan *parallelChunk, workers)
+	stop := make(chan struct{})
+	var readErr error
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		readErr = readChunks(reader, countPositions, func(chunk *parallelChunk) bool {
+			select {
+			case pending <- chunk:
+			case <-stop:
+				return false
+			}
+			select {
+			case jobs <- chunk:
+				return true
+			case <-stop:
+				return false
+			}
+		})
+	}()
+	for range workers {
+		go func() {
+			for chunk := range jobs {
+				transform(chunk)
+				close(chunk.done)
+			}
+		}()
+	}
+
+	var err error
+	for chunk := range pending {
+		if err != nil {
+			continue // Drain the chunks, so that the reading goroutine does not block on them.
+		}
+		<-chunk.done
+		if _, err = writer.Write(chunk.output); err == nil {
+			err = chunk.err
+		}
+		chunk.release()
+		if err != nil {
+			close(stop)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return readErr
+}
+
+// readChunks passes the chunks read to the send function until io.EOF, or until the function refuses them.
+// When counting positions, the bytes of a rune cut off at the end of a chunk are moved to the next one. They
+// are copied out before the chunk is sent, as its buffer may be released as soon as it has been written.
+func readChunks(reader io.Reader, countPositions func(input []byte) int, send func(chunk *parallelChunk) bool) error {
+	var carriedRune [utf8.UTFMax]byte
+	carried := carriedRune[:0]
+	var offset int64
+	position := 0
+	for {
+		inputBuffer, buffer := getSlice(ParallelChunkSize)
+		carriedSize := copy(buffer, carried)
+		readSize, err := io.ReadFull(reader, buffer[carriedSize:])
+		atEOF := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !atEOF {
+			putBuffer(ParallelChunkSize, inputBuffer)
+			return err
+		}
+		input := buffer[:carriedSize+readSize]
+		boundary := len(input)
+		if countPositions != nil && !atEOF {
+			boundary = lastRuneBoundary(input)
+		}
+		carried = append(carriedRune[:0], input[boundary:]...)
+		if boundary == 0 {
+			putBuffer(ParallelChunkSize, inputBuffer)
+			return nil
+		}
+		chunk := &parallelChunk{
+			inputBuffer: inputBuffer,
+			input:       input[:boundary],
+			offset:      offset,
+			position:    position,
+			done:        make(chan struct{}),
+		}
+		offset += int64(boundary)
+		if countPositions != nil {
+			position += countPositions(chunk.input)
+		}
+		if !send(chunk) || atEOF {
+			return nil
+		}
+	}
+}
+
+// lastRuneBoundary returns the length of the input without the incomplete rune it may end with.
+func lastRuneBoundary(input []byte) int {
+	for i := len(input) - 1; i >= max(0, len(input)-utf8.UTFMax); i-- {
+		if utf8.RuneStart(input[i]) {
+			if utf8.FullRune(input[i:]) {
+				return len(input)
+			}
+			return i
+		}
+	}
+	return len(input)
+}
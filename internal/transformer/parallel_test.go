@@ -0,0 +1,177 @@
+package transformer
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// parallelTestInput spans several chunks, and its multibyte runes cross the chunk boundaries.
+var parallelTestInput = strings.Repeat("Zażółć gęślą jaźń ✈ 𝄞 ", 3*ParallelChunkSize/30)
+
+func newPositionalTestFunc() func(int, rune) rune {
+	transformFunc, err := algorithms.NewVigenereRuneFunc("lemon", true)
+	if err != nil {
+		panic(err)
+	}
+	return transformFunc
+}
+
+func Test_ApplyPositionalFuncInParallel(t *testing.T) {
+	// given
+	expected := new(bytes.Buffer)
+	assert.NoError(t, ApplyPositionalFuncAndTransfer(strings.NewReader(parallelTestInput), expected,
+		bytes.NewBuffer(make([]byte, 0, ReadBufferSize)), bytes.NewBuffer(make([]byte, 0, WriteBufferSize)),
+		newPositionalTestFunc()))
+	output := new(bytes.Buffer)
+	// when
+	err := ApplyPositionalFuncInParallel(iotest.HalfReader(strings.NewReader(parallelTestInput)), output, newPositionalTestFunc(), InvalidError, 4)
+	// then
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(expected.Bytes(), output.Bytes()))
+}
+
+func Test_ApplyPositionalFuncInParallel_invalidRunes(t *testing.T) {
+	// given
+	inputs := []string{
+		parallelTestInput + "\xF0\x9F",
+		strings.Repeat("a", ParallelChunkSize+10) + "\xFF" + parallelTestInput,
+	}
+	expectedErrs := []error{
+		&ErrInvalidUTF8{int64(len(parallelTestInput)), []byte{0xF0, 0x9F}},
+		&ErrInvalidUTF8{ParallelChunkSize + 10, []byte{0xFF}},
+	}
+	for i, input := range inputs {
+		// when
+		err := ApplyPositionalFuncInParallel(strings.NewReader(input), io.Discard, newPositionalTestFunc(), InvalidError, 4)
+		// then
+		assert.Equal(t, expectedErrs[i], err)
+	}
+}
+
+func Test_ApplyPositionalFuncInParallel_invalidPolicies(t *testing.T) {
+	// given
+	input := strings.Repeat("a\xFFb", ParallelChunkSize/2) + "\xE2\x9C"
+	for _, policy := range []InvalidPolicy{InvalidReplace, InvalidSkip, InvalidPassthrough} {
+		expected := new(bytes.Buffer)
+		assert.NoError(t, PooledApplyPositionalFuncAndTransfer(strings.NewReader(input), expected, ReadBufferSize,
+			newPositionalTestFunc(), policy))
+		output := new(bytes.Buffer)
+		// when
+		err := ApplyPositionalFuncInParallel(strings.NewReader(input), output, newPositionalTestFunc(), policy, 4)
+		// then
+		assert.NoError(t, err, policy)
+		assert.True(t, bytes.Equal(expected.Bytes(), output.Bytes()), policy)
+	}
+}
+
+func Test_ApplyPositionalFuncInParallel_writeError(t *testing.T) {
+	// given
+	writeErr := errors.New("disk full")
+	// when
+	err := ApplyPositionalFuncInParallel(strings.NewReader(parallelTestInput), &failingWriter{writeErr}, newPositionalTestFunc(), InvalidError, 4)
+	// then
+	assert.Equal(t, writeErr, err)
+}
+
+func Test_ApplyPositionalFuncInParallel_empty(t *testing.T) {
+	// given
+	output := new(bytes.Buffer)
+	// when
+	err := ApplyPositionalFuncInParallel(strings.NewReader(""), output, newPositionalTestFunc(), InvalidError, 4)
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, output.Len())
+}
+
+func Test_ApplyByteFuncInParallel(t *testing.T) {
+	// given
+	input := []byte(parallelTestInput + "\xFF\x00")
+	xorByteFunc, _ := algorithms.NewXorByteFunc(0x5A)
+	expected := make([]byte, len(input))
+	for i, b := range input {
+		expected[i] = xorByteFunc(b)
+	}
+	output := new(bytes.Buffer)
+	// when
+	err := ApplyByteFuncInParallel(bytes.NewReader(input), output, xorByteFunc, 3)
+	// then
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(expected, output.Bytes()))
+}
+
+func Test_lastRuneBoundary(t *testing.T) {
+	// given
+	inputs := []string{"", "abc", "ab\xC5", "ab\xF0\x9D\x84", "ab\xF0\x9D\x84\x9E", "ab\x80\x80\x80\x80"}
+	expectedBoundaries := []int{0, 3, 2, 2, 6, 6}
+	for i, input := range inputs {
+		// when
+		boundary := lastRuneBoundary([]byte(input))
+		// then
+		assert.Equal(t, expectedBoundaries[i], boundary, input)
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (writer *failingWriter) Write([]byte) (int, error) {
+	return 0, writer.err
+}
+
+func BenchmarkApplyPositionalFuncAndTransfer(b *testing.B) {
+	transformFunc := newPositionalTestFunc()
+	b.SetBytes(int64(len(parallelTestInput)))
+	b.ReportAllocs()
+	for range b.N {
+		inputBuffer := bytes.NewBuffer(make([]byte, 0, ReadBufferSize))
+		outputBuffer := bytes.NewBuffer(make([]byte, 0, WriteBufferSize))
+		err := ApplyPositionalFuncAndTransfer(strings.NewReader(parallelTestInput), io.Discard, inputBuffer, outputBuffer, transformFunc)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkApplyPositionalFuncInParallel(b *testing.B) {
+	transformFunc := newPositionalTestFunc()
+	b.SetBytes(int64(len(parallelTestInput)))
+	b.ReportAllocs()
+	for range b.N {
+		err := ApplyPositionalFuncInParallel(strings.NewReader(parallelTestInput), io.Discard, transformFunc, InvalidError, runtime.GOMAXPROCS(0))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkApplyByteFuncAndTransfer(b *testing.B) {
+	xorByteFunc, _ := algorithms.NewXorByteFunc(0x5A)
+	b.SetBytes(int64(len(parallelTestInput)))
+	b.ReportAllocs()
+	for range b.N {
+		err := ApplyByteFuncAndTransfer(strings.NewReader(parallelTestInput), io.Discard, make([]byte, ReadBufferSize), xorByteFunc)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkApplyByteFuncInParallel(b *testing.B) {
+	xorByteFunc, _ := algorithms.NewXorByteFunc(0x5A)
+	b.SetBytes(int64(len(parallelTestInput)))
+	b.ReportAllocs()
+	for range b.N {
+		err := ApplyByteFuncInParallel(strings.NewReader(parallelTestInput), io.Discard, xorByteFunc, runtime.GOMAXPROCS(0))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package transformer
+
+import (
+	"bytes"
+	"io"
+	"math/bits"
+	"sync"
+	"unicode/utf8"
+)
+
+// bufferPools holds a sync.Pool of the *bytes.Buffer for each of the buffer sizes in use, since the
+// read buffer size sets the size of the buffers. The sizes are the ones of poolBufferSize, so there are
+// only a few of them.
+var bufferPools sync.Map
+
+// poolBufferSize rounds the read buffer size up to the power of two within MinBufferSize and MaxBufferSize.
+func poolBufferSize(size int) int {
+	size = min(max(size, MinBufferSize), MaxBufferSize)
+	return 1 << bits.Len(uint(size-1))
+}
+
+func getBuffer(size int) *bytes.Buffer {
+	pool, ok := bufferPools.Load(size)
+	if !ok {
+		pool, _ = bufferPools.LoadOrStore(size, &sync.Pool{New: func() any {
+			return bytes.NewBuffer(make([]byte, 0, size))
+		}})
+	}
+	return pool.(*sync.Pool).Get().(*bytes.Buffer)
+}
+
+// putBuffer drops the buffer grown beyond its size, so that the pool keeps handing out the buffers of the size.
+func putBuffer(size int, buffer *bytes.Buffer) {
+	if buffer.Cap() != size {
+		return
+	}
+	buffer.Reset()
+	pool, _ := bufferPools.Load(size)
+	pool.(*sync.Pool).Put(buffer)
+}
+
+// getSlice returns the whole of the pooled buffer of the size as a slice, to be given back with putBuffer.
+func getSlice(size int) (*bytes.Buffer, []byte) {
+	buffer := getBuffer(size)
+	return buffer, buffer.AvailableBuffer()[:size]
+}
+
+// PooledApplyPositionalFuncAndTransfer works like ApplyPositionalFuncAndTransfer, but takes the buffers,
+// whose sizes are given by the read buffer size rounded with poolBufferSize, from a pool shared with the
+// other transfers, and handles the invalid bytes according to the policy.
+func PooledApplyPositionalFuncAndTransfer(
+	reader io.Reader,
+	writer io.Writer,
+	readBufferSize int,
+	transformFunc func(position int, r rune) rune,
+	policy InvalidPolicy,
+) error {
+	readBufferSize = poolBufferSize(readBufferSize)
+	inputBuffer := getBuffer(readBufferSize)
+	defer putBuffer(readBufferSize, inputBuffer)
+	outputBuffer := getBuffer(writeBufferSize(readBufferSize))
+	defer putBuffer(writeBufferSize(readBufferSize), outputBuffer)
+	// The reads leave room for the bytes of an incomplete rune, so that the pooled buffer does not grow.
+	readSize := readBufferSize - (utf8.UTFMax - 1)
+	return applyFuncAndTransfer(
+		reader, writer, inputBuffer, outputBuffer, readSize, newRuneTransformer(WithPosition(transformFunc), policy),
+	)
+}
+
+// PooledApplyByteFuncAndTransfer is the byte by byte counterpart of PooledApplyPositionalFuncAndTransfer.
+func PooledApplyByteFuncAndTransfer(reader io.Reader, writer io.Writer, bufferSize int, transformFunc func(b byte) byte) error {
+	bufferSize = poolBufferSize(bufferSize)
+	pooledBuffer, buffer := getSlice(bufferSize)
+	defer putBuffer(bufferSize, pooledBuffer)
+	return ApplyByteFuncAndTransfer(reader, writer, buffer, transformFunc)
+}
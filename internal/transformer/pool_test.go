@@ -0,0 +1,150 @@
+package transformer
+
+import (
+	"bytes"
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func Test_PooledApplyPositionalFuncAndTransfer_bufferSizes(t *testing.T) {
+	// given
+	input := strings.Repeat(streamInput, 100)
+	expected := transformString(input, transformFunc)
+	for _, bufferSize := range []int{0, MinBufferSize, 7, ReadBufferSize, 64 * 1024} {
+		output := new(bytes.Buffer)
+		// when
+		err := PooledApplyPositionalFuncAndTransfer(iotest.HalfReader(strings.NewReader(input)), output, bufferSize,
+			func(_ int, r rune) rune { return transformFunc(r) }, InvalidError)
+		// then
+		assert.NoError(t, err, bufferSize)
+		assert.Equal(t, expected, output.String(), bufferSize)
+	}
+}
+
+func Test_PooledApplyByteFuncAndTransfer_bufferSizes(t *testing.T) {
+	// given
+	input := []byte(strings.Repeat(streamInput, 100))
+	xorByteFunc, _ := algorithms.NewXorByteFunc(0x5A)
+	expected := make([]byte, len(input))
+	for i, b := range input {
+		expected[i] = xorByteFunc(b)
+	}
+	for _, bufferSize := range []int{0, 1, 13, ReadBufferSize} {
+		output := new(bytes.Buffer)
+		// when
+		err := PooledApplyByteFuncAndTransfer(bytes.NewReader(input), output, bufferSize, xorByteFunc)
+		// then
+		assert.NoError(t, err, bufferSize)
+		assert.Equal(t, expected, output.Bytes(), bufferSize)
+	}
+}
+
+func Test_poolBufferSize(t *testing.T) {
+	// given
+	sizes := []int{-1, 0, 1, MinBufferSize, 5, 1000, ReadBufferSize, ReadBufferSize + 1, MaxBufferSize, 1 << 40}
+	expectedSizes := []int{4, 4, 4, 4, 8, 1024, ReadBufferSize, 2 * ReadBufferSize, MaxBufferSize, MaxBufferSize}
+	for i, size := range sizes {
+		// when
+		result := poolBufferSize(size)
+		// then
+		assert.Equal(t, expectedSizes[i], result, size)
+	}
+}
+
+func Test_PooledApplyPositionalFuncAndTransfer_reusesBuffers(t *testing.T) {
+	// given
+	input := strings.NewReader(strings.Repeat("Hello, World! ", 1000))
+	positionalFunc := func(_ int, r rune) rune { return transformFunc(r) }
+	// when
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = input.Seek(0, io.SeekStart)
+		if err := PooledApplyPositionalFuncAndTransfer(input, io.Discard, ReadBufferSize, positionalFunc, InvalidError); err != nil {
+			panic(err)
+		}
+	})
+	// then
+	assert.LessOrEqual(t, allocs, 4.0)
+}
+
+func Test_PooledApplyPositionalFuncAndTransfer_keepsBufferCapacity(t *testing.T) {
+	// given
+	input := strings.Repeat(streamInput, 100)
+	positionalFunc := func(_ int, r rune) rune { return transformFunc(r) }
+	for _, bufferSize := range []int{MinBufferSize, 8, ReadBufferSize} {
+		for range 3 {
+			// when
+			err := PooledApplyPositionalFuncAndTransfer(
+				iotest.HalfReader(strings.NewReader(input)), io.Discard, bufferSize, positionalFunc, InvalidError,
+			)
+			inputBuffer := getBuffer(bufferSize)
+			outputBuffer := getBuffer(writeBufferSize(bufferSize))
+			// then
+			assert.NoError(t, err, bufferSize)
+			assert.Equal(t, bufferSize, inputBuffer.Cap(), bufferSize)
+			assert.Equal(t, writeBufferSize(bufferSize), outputBuffer.Cap(), bufferSize)
+			putBuffer(bufferSize, inputBuffer)
+			putBuffer(writeBufferSize(bufferSize), outputBuffer)
+		}
+	}
+}
+
+func Test_putBuffer_dropsGrownBuffer(t *testing.T) {
+	// given
+	buffer := getBuffer(MinBufferSize)
+	buffer.Grow(2 * MinBufferSize)
+	// when
+	putBuffer(MinBufferSize, buffer)
+	// then
+	assert.Equal(t, MinBufferSize, getBuffer(MinBufferSize).Cap())
+}
+
+func benchmarkPooledApplyPositionalFuncAndTransfer(b *testing.B, input string, bufferSize int) {
+	positionalFunc := func(_ int, r rune) rune { return transformFunc(r) }
+	reader := strings.NewReader(input)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for range b.N {
+		reader.Reset(input)
+		if err := PooledApplyPositionalFuncAndTransfer(reader, io.Discard, bufferSize, positionalFunc, InvalidError); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+var (
+	benchmarkASCIIInput   = strings.Repeat("It was the best of times, it was the worst of times. ", 20_000)
+	benchmarkUnicodeInput = strings.Repeat("Zażółć gęślą jaźń, ✈ 𝄞. ", 20_000)
+)
+
+func BenchmarkPooledApplyPositionalFuncAndTransfer_ascii(b *testing.B) {
+	benchmarkPooledApplyPositionalFuncAndTransfer(b, benchmarkASCIIInput, ReadBufferSize)
+}
+
+func BenchmarkPooledApplyPositionalFuncAndTransfer_unicode(b *testing.B) {
+	benchmarkPooledApplyPositionalFuncAndTransfer(b, benchmarkUnicodeInput, ReadBufferSize)
+}
+
+func BenchmarkPooledApplyPositionalFuncAndTransfer_smallBuffer(b *testing.B) {
+	benchmarkPooledApplyPositionalFuncAndTransfer(b, benchmarkASCIIInput, 512)
+}
+
+func BenchmarkPooledApplyPositionalFuncAndTransfer_largeBuffer(b *testing.B) {
+	benchmarkPooledApplyPositionalFuncAndTransfer(b, benchmarkASCIIInput, 256*1024)
+}
+
+func BenchmarkPooledApplyByteFuncAndTransfer(b *testing.B) {
+	xorByteFunc, _ := algorithms.NewXorByteFunc(0x5A)
+	reader := strings.NewReader(benchmarkASCIIInput)
+	b.SetBytes(int64(len(benchmarkASCIIInput)))
+	b.ReportAllocs()
+	for range b.N {
+		reader.Reset(benchmarkASCIIInput)
+		if err := PooledApplyByteFuncAndTransfer(reader, io.Discard, ReadBufferSize, xorByteFunc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package transformer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// Reader transforms the runes read from the underlying reader. The UTF-8 sequences split between its
+// reads are joined before transforming, and the invalid bytes, including an incomplete rune left at io.EOF,
+// are handled according to the InvalidPolicy.
+type Reader struct {
+	reader       io.Reader
+	transformer  *runeTransformer
+	input        []byte
+	outputBuffer []byte
+	output       []byte
+	err          error
+}
+
+func NewReader(reader io.Reader, transformFunc func(r rune) rune, policy InvalidPolicy) *Reader {
+	return &Reader{
+		reader:       reader,
+		transformer:  newRuneTransformer(transformFunc, policy),
+		input:        make([]byte, 0, ReadBufferSize),
+		outputBuffer: make([]byte, 0, WriteBufferSize),
+	}
+}
+
+func (reader *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(reader.output) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		reader.fill()
+	}
+	n := copy(p, reader.output)
+	reader.output = reader.output[n:]
+	return n, nil
+}
+
+func (reader *Reader) fill() {
+	input := reader.input
+	readSize, err := reader.reader.Read(input[len(input):cap(input)])
+	input = input[:len(input)+readSize]
+
+	output, consumed, transformErr := reader.transformer.transform(reader.outputBuffer[:0], input, errors.Is(err, io.EOF))
+	reader.output = output
+	reader.input = input[:copy(input, input[consumed:])]
+
+	if transformErr != nil {
+		reader.err = transformErr
+	} else {
+		reader.err = err
+	}
+}
+
+// Writer transforms the runes written to it and passes them to the underlying writer. The bytes of a rune
+// split between the writes are held until the rune is complete, so Close has to be called to handle
+// the incomplete rune left behind according to the InvalidPolicy. Close does not close the underlying writer.
+type Writer struct {
+	writer       io.Writer
+	transformer  *runeTransformer
+	input        []byte
+	outputBuffer []byte
+	closed       bool
+}
+
+func NewWriter(writer io.Writer, transformFunc func(r rune) rune, policy InvalidPolicy) *Writer {
+	return &Writer{
+		writer:       writer,
+		transformer:  newRuneTransformer(transformFunc, policy),
+		input:        make([]byte, 0, ReadBufferSize+utf8.UTFMax),
+		outputBuffer: make([]byte, 0, WriteBufferSize),
+	}
+}
+
+// Write copies the chunks of p after the bytes of the incomplete rune held from the previous writes.
+func (writer *Writer) Write(p []byte) (int, error) {
+	if writer.closed {
+		return 0, ErrClosedWriter
+	}
+	written := 0
+	for written < len(p) {
+		pendingSize := len(writer.input)
+		chunkSize := copy(writer.input[pendingSize:ReadBufferSize+pendingSize], p[written:])
+		input := writer.input[:pendingSize+chunkSize]
+		output, consumed, err := writer.transformer.transform(writer.outputBuffer[:0], input, false)
+		if _, writeErr := writer.writer.Write(output); writeErr != nil {
+			return written, writeErr
+		}
+		if err != nil {
+			return written + max(0, consumed-pendingSize), err
+		}
+		writer.input = input[:copy(input, input[consumed:])]
+		written += chunkSize
+	}
+	return written, nil
+}
+
+// Close handles the incomplete rune the written data may have ended with, which is an ErrInvalidUTF8 by
+// default.
+func (writer *Writer) Close() error {
+	if writer.closed {
+		return nil
+	}
+	writer.closed = true
+	if len(writer.input) == 0 {
+		return nil
+	}
+	output, _, err := writer.transformer.transform(writer.outputBuffer[:0], writer.input, true)
+	if _, writeErr := writer.writer.Write(output); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// appendTransformedASCII skips decoding the runes, which are all single bytes, and encodes only those
+// transformed out of the ASCII range.
+func appendTransformedASCII(output []byte, input []byte, transformFunc func(r rune) rune) ([]byte, int) {
+	output = slices.Grow(output, len(input))
+	for i, b := range input {
+		transformedRune := transformFunc(rune(b))
+		switch {
+		case uint32(transformedRune) < utf8.RuneSelf:
+			output = append(output, byte(transformedRune))
+		case utf8.ValidRune(transformedRune):
+			output = utf8.AppendRune(output, transformedRune)
+		default:
+			return output, i
+		}
+	}
+	return output, len(input)
+}
+
+// isASCII checks the input eight bytes at a time.
+func isASCII(input []byte) bool {
+	const highBits = 0x8080808080808080
+	i := 0
+	for ; i+8 <= len(input); i += 8 {
+		if binary.LittleEndian.Uint64(input[i:])&highBits != 0 {
+			return false
+		}
+	}
+	for ; i < len(input); i++ {
+		if input[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// ByteReader is the byte by byte counterpart of Reader.
+type ByteReader struct {
+	reader        io.Reader
+	transformFunc func(b byte) byte
+}
+
+func NewByteReader(reader io.Reader, transformFunc func(b byte) byte) *ByteReader {
+	return &ByteReader{reader, transformFunc}
+}
+
+func (reader *ByteReader) Read(p []byte) (int, error) {
+	n, err := reader.reader.Read(p)
+	for i, b := range p[:n] {
+		p[i] = reader.transformFunc(b)
+	}
+	return n, err
+}
+
+// ByteWriter is the byte by byte counterpart of Writer. As there are no incomplete units to be left
+// behind, its Close only prevents further writes.
+type ByteWriter struct {
+	writer        io.Writer
+	transformFunc func(b byte) byte
+	outputBuffer  []byte
+	closed        bool
+}
+
+func NewByteWriter(writer io.Writer, transformFunc func(b byte) byte) *ByteWriter {
+	return &ByteWriter{writer: writer, transformFunc: transformFunc, outputBuffer: make([]byte, ReadBufferSize)}
+}
+
+func (writer *ByteWriter) Write(p []byte) (int, error) {
+	if writer.closed {
+		return 0, ErrClosedWriter
+	}
+	written := 0
+	for written < len(p) {
+		chunk := writer.outputBuffer[:copy(writer.outputBuffer, p[written:])]
+		for i, b := range chunk {
+			chunk[i] = writer.transformFunc(b)
+		}
+		n, err := writer.writer.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (writer *ByteWriter) Close() error {
+	writer.closed = true
+	return nil
+}
+
+var ErrClosedWriter = errors.New("write to a closed writer")
@@ -0,0 +1,198 @@
+package transformer
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const streamInput = "Hello, World! Zażółć gęślą jaźń ✈ 𝄞"
+
+func transformString(input string, transformFunc func(rune) rune) string {
+	return strings.Map(transformFunc, input)
+}
+
+func Test_Reader_iotest(t *testing.T) {
+	// given
+	expected := transformString(streamInput, transformFunc)
+	readers := map[string]io.Reader{
+		"plain":    strings.NewReader(streamInput),
+		"one byte": iotest.OneByteReader(strings.NewReader(streamInput)),
+		"half":     iotest.HalfReader(strings.NewReader(streamInput)),
+		"data err": iotest.DataErrReader(strings.NewReader(streamInput)),
+	}
+	for name, reader := range readers {
+		// when
+		err := iotest.TestReader(NewReader(reader, transformFunc, InvalidError), []byte(expected))
+		// then
+		assert.NoError(t, err, name)
+	}
+}
+
+func Test_Reader_largeInput(t *testing.T) {
+	// given
+	input := strings.Repeat(streamInput, 1000)
+	expected := transformString(input, transformFunc)
+	reader := NewReader(iotest.HalfReader(strings.NewReader(input)), transformFunc, InvalidError)
+	// when
+	result, err := io.ReadAll(reader)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(result))
+}
+
+func Test_Reader_trailingIncompleteRune(t *testing.T) {
+	// given
+	input := append([]byte("abc"), inputRuneBytes[:2]...)
+	reader := NewReader(iotest.OneByteReader(bytes.NewReader(input)), transformFunc, InvalidError)
+	// when
+	result, err := io.ReadAll(reader)
+	// then
+	assert.Equal(t, &ErrInvalidUTF8{3, inputRuneBytes[:2]}, err)
+	assert.Equal(t, transformString("abc", transformFunc), string(result))
+}
+
+func Test_Reader_invalidRune(t *testing.T) {
+	// given
+	input := []byte{'a', 0xFF, 'b'}
+	reader := NewReader(bytes.NewReader(input), transformFunc, InvalidError)
+	// when
+	result, err := io.ReadAll(reader)
+	// then
+	assert.Equal(t, &ErrInvalidUTF8{1, []byte{0xFF}}, err)
+	assert.Equal(t, transformString("a", transformFunc), string(result))
+}
+
+func Test_Reader_readError(t *testing.T) {
+	// given
+	reader := NewReader(iotest.ErrReader(iotest.ErrTimeout), transformFunc, InvalidError)
+	// when
+	_, err := io.ReadAll(reader)
+	// then
+	assert.Equal(t, iotest.ErrTimeout, err)
+}
+
+func Test_Writer_splitWrites(t *testing.T) {
+	// given
+	expected := transformString(streamInput, transformFunc)
+	input := []byte(streamInput)
+	for _, chunkSize := range []int{1, 2, 3, 5, 7, len(input)} {
+		output := new(bytes.Buffer)
+		writer := NewWriter(output, transformFunc, InvalidError)
+		// when
+		for i := 0; i < len(input); i += chunkSize {
+			n, err := writer.Write(input[i:min(len(input), i+chunkSize)])
+			assert.NoError(t, err)
+			assert.Equal(t, min(len(input), i+chunkSize)-i, n)
+		}
+		err := writer.Close()
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, expected, output.String(), chunkSize)
+	}
+}
+
+func Test_Writer_largeWrite(t *testing.T) {
+	// given
+	input := strings.Repeat(streamInput, 1000)
+	expected := transformString(input, transformFunc)
+	output := new(bytes.Buffer)
+	writer := NewWriter(output, transformFunc, InvalidError)
+	// when
+	n, writeErr := io.WriteString(writer, input)
+	closeErr := writer.Close()
+	// then
+	assert.NoError(t, writeErr)
+	assert.NoError(t, closeErr)
+	assert.Equal(t, len(input), n)
+	assert.Equal(t, expected, output.String())
+}
+
+func Test_Writer_trailingIncompleteRune(t *testing.T) {
+	// given
+	output := new(bytes.Buffer)
+	writer := NewWriter(output, transformFunc, InvalidError)
+	// when
+	_, writeErr := writer.Write(inputRuneBytes[:2])
+	closeErr := writer.Close()
+	// then
+	assert.NoError(t, writeErr)
+	assert.Equal(t, &ErrInvalidUTF8{0, inputRuneBytes[:2]}, closeErr)
+	assert.Equal(t, 0, output.Len())
+}
+
+func Test_Writer_invalidRune(t *testing.T) {
+	// given
+	output := new(bytes.Buffer)
+	writer := NewWriter(output, transformFunc, InvalidError)
+	// when
+	n, err := writer.Write([]byte{'a', 0xFF, 'b'})
+	// then
+	assert.Equal(t, &ErrInvalidUTF8{1, []byte{0xFF}}, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, transformString("a", transformFunc), output.String())
+}
+
+func Test_Writer_writeAfterClose(t *testing.T) {
+	// given
+	writer := NewWriter(new(bytes.Buffer), transformFunc, InvalidError)
+	// when
+	closeErr := writer.Close()
+	_, writeErr := writer.Write([]byte("a"))
+	// then
+	assert.NoError(t, closeErr)
+	assert.Equal(t, ErrClosedWriter, writeErr)
+}
+
+func Test_ByteReader_iotest(t *testing.T) {
+	// given
+	input := []byte{0x00, 0xFF, 0xFE, 'a', 'b'}
+	expected := make([]byte, len(input))
+	for i, b := range input {
+		expected[i] = xorByteFunc(b)
+	}
+	// when
+	err := iotest.TestReader(NewByteReader(bytes.NewReader(input), xorByteFunc), expected)
+	// then
+	assert.NoError(t, err)
+}
+
+func Test_ByteWriter_roundTrip(t *testing.T) {
+	// given
+	input := bytes.Repeat([]byte{0x00, 0xFF, 0x80}, ReadBufferSize)
+	output := new(bytes.Buffer)
+	writer := NewByteWriter(NewByteWriter(output, xorByteFunc), xorByteFunc)
+	// when
+	n, err := writer.Write(input)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(input), n)
+	assert.Equal(t, input, output.Bytes())
+}
+
+func Test_runeTransformer_ascii(t *testing.T) {
+	// given
+	input := []byte("Hello, World! ~")
+	expected := transformString(string(input), transformFunc)
+	// when
+	output, consumed, err := newRuneTransformer(transformFunc, InvalidError).transform(nil, input, false)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(input), consumed)
+	assert.Equal(t, expected, string(output))
+}
+
+func Test_isASCII(t *testing.T) {
+	// given
+	inputs := []string{"", "Hello", "Hello, World!", "Hello, Wörld!", "Hello, World!ż", "\x7F\x7F\x7F\x7F\x7F\x7F\x7F\x7F"}
+	expected := []bool{true, true, true, false, false, true}
+	for i, input := range inputs {
+		// when
+		result := isASCII([]byte(input))
+		// then
+		assert.Equal(t, expected[i], result, input)
+	}
+}
@@ -5,14 +5,27 @@ import (
 	"errors"
 	"io"
 	"os"
+	"unicode/utf8"
 )
 
 // WriteBufferSize by making it 4 times, we guarantee that it will be able to fit 4kb of transformed runes.
+// Both are the defaults, the read buffer has to fit at least MinBufferSize bytes, the longest rune, and
+// at most MaxBufferSize bytes, so that the write buffer stays within a few tens of megabytes.
 const (
 	ReadBufferSize  = 4 * 1024
 	WriteBufferSize = 4 * ReadBufferSize
+	MinBufferSize   = utf8.UTFMax
+	MaxBufferSize   = 16 * 1024 * 1024
 )
 
+func writeBufferSize(readBufferSize int) int {
+	return WriteBufferSize / ReadBufferSize * readBufferSize
+}
+
+// StdStreamPath stands for the standard input when given as the input path, and for the standard output
+// when given as the output path.
+const StdStreamPath = "-"
+
 func FilesApplyFuncAndTransfer(
 	inputFilePath string,
 	outputFilePath string,
@@ -20,111 +33,137 @@ func FilesApplyFuncAndTransfer(
 	outputBuffer *bytes.Buffer,
 	transformFunc func(r rune) rune,
 ) error {
-	inputFile, err := os.Open(inputFilePath)
-	if err != nil {
-		return err
+	return FilesTransfer(inputFilePath, outputFilePath, func(reader io.Reader, writer io.Writer) error {
+		return ApplyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, transformFunc)
+	})
+}
+
+// FilesApplyPositionalFuncAndTransfer works like FilesApplyFuncAndTransfer, but the transform function
+// also receives the position of the rune in the whole file, which is kept across the consecutive reads.
+func FilesApplyPositionalFuncAndTransfer(
+	inputFilePath string,
+	outputFilePath string,
+	inputBuffer *bytes.Buffer,
+	outputBuffer *bytes.Buffer,
+	transformFunc func(position int, r rune) rune,
+) error {
+	return FilesApplyFuncAndTransfer(inputFilePath, outputFilePath, inputBuffer, outputBuffer, WithPosition(transformFunc))
+}
+
+// ApplyPositionalFuncAndTransfer is the io.Reader and io.Writer counterpart of FilesApplyPositionalFuncAndTransfer.
+func ApplyPositionalFuncAndTransfer(
+	reader io.Reader,
+	writer io.Writer,
+	inputBuffer *bytes.Buffer,
+	outputBuffer *bytes.Buffer,
+	transformFunc func(position int, r rune) rune,
+) error {
+	return ApplyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, WithPosition(transformFunc))
+}
+
+// WithPosition adapts a position-aware transform function to the plain one, counting the runes itself.
+// The returned function has to be called exactly once per rune, in the stream order.
+func WithPosition(transformFunc func(position int, r rune) rune) func(rune) rune {
+	return withPositionFrom(0, transformFunc)
+}
+
+// withPositionFrom works like WithPosition for the runes following the given number of runes.
+func withPositionFrom(position int, transformFunc func(position int, r rune) rune) func(rune) rune {
+	return func(r rune) rune {
+		transformedRune := transformFunc(position, r)
+		position++
+		return transformedRune
+	}
+}
+
+// FilesTransfer opens the input and the output file, or takes the standard streams for StdStreamPath,
+// and hands them over to the transfer function.
+func FilesTransfer(inputFilePath string, outputFilePath string, transfer func(io.Reader, io.Writer) error) (err error) {
+	inputFile := os.Stdin
+	if inputFilePath != StdStreamPath {
+		if inputFile, err = os.Open(inputFilePath); err != nil {
+			return err
+		}
+		defer closeFile(inputFile, &err)
 	}
-	defer safeCloseFile(inputFile)
 
-	outputFile, err := os.OpenFile(outputFilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
+	outputFile := os.Stdout
+	if outputFilePath != StdStreamPath {
+		if outputFile, err = os.OpenFile(outputFilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644); err != nil {
+			return err
+		}
+		defer closeFile(outputFile, &err)
 	}
-	defer safeCloseFile(outputFile)
 
-	return applyFuncAndTransfer(inputFile, outputFile, inputBuffer, outputBuffer, transformFunc)
+	return transfer(inputFile, outputFile)
 }
 
-func safeCloseFile(file *os.File) {
-	if err := file.Close(); err != nil {
-		panic(err)
+// closeFile reports the close failure through the err, unless an earlier failure is already reported,
+// as for the output file it may mean that the written data did not make it to the disk.
+func closeFile(file *os.File, err *error) {
+	if closeErr := file.Close(); closeErr != nil && *err == nil {
+		*err = closeErr
 	}
 }
 
-func applyFuncAndTransfer(
+// ApplyFuncAndTransfer reads the UTF-8 encoded runes from the reader until io.EOF, transforms them
+// and writes them to the writer. The input buffer capacity limits the size of a single read.
+func ApplyFuncAndTransfer(
 	reader io.Reader,
 	writer io.Writer,
 	inputBuffer *bytes.Buffer,
 	outputBuffer *bytes.Buffer,
 	transformFunc func(rune) rune,
 ) error {
-	inputBufferCapacity := int64(inputBuffer.Cap())
-	limitedReader := io.LimitedReader{R: reader, N: inputBufferCapacity}
+	return applyFuncAndTransfer(
+		reader, writer, inputBuffer, outputBuffer, inputBuffer.Cap(), newRuneTransformer(transformFunc, InvalidError),
+	)
+}
 
-	consecutiveInvalidRune := false
+// applyFuncAndTransfer reads at most readSize bytes at a time on top of the bytes of an incomplete rune left
+// in the input buffer, which grows only if it cannot fit them.
+func applyFuncAndTransfer(
+	reader io.Reader,
+	writer io.Writer,
+	inputBuffer *bytes.Buffer,
+	outputBuffer *bytes.Buffer,
+	readSize int,
+	transformer *runeTransformer,
+) error {
 	for {
-		copiedLimitedReader := limitedReader
-		readSize, err := inputBuffer.ReadFrom(&copiedLimitedReader)
-		if err != nil {
+		inputBuffer.Grow(readSize)
+		chunk := inputBuffer.AvailableBuffer()[:readSize]
+		n, err := io.ReadFull(reader, chunk)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
 			return err
 		}
-		if readSize == 0 {
-			if consecutiveInvalidRune || inputBuffer.Len() != 0 {
-				return ErrUnableToTransformRune
-			}
-			break
-		}
-
-		err = runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformFunc)
-
-		switch {
-		case err == nil, errors.Is(err, ErrErroneousRune): // something was transformed, so write it
-			consecutiveInvalidRune = false
-			if _, err = outputBuffer.WriteTo(writer); err != nil {
-				return err
-			}
-		case errors.Is(err, ErrErroneousInitialRune):
-			if consecutiveInvalidRune {
-				return ErrUnableToTransformRune
-			}
-			consecutiveInvalidRune = true
-		default:
+		_, _ = inputBuffer.Write(chunk[:n])
+		atEOF := n == 0
+		transformErr := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformer, atEOF)
+		if _, err = outputBuffer.WriteTo(writer); err != nil {
 			return err
 		}
+		if transformErr != nil || atEOF {
+			return transformErr
+		}
 	}
-	return nil
 }
 
 // The input buffer is expected to be ready to be read from.
 // The output buffer is expected to be ready to be written to.
-// At the end, the input buffer is prepared to be written to again.
+// At the end, the input buffer is prepared to be written to again, holding the bytes of an incomplete rune
+// left untransformed until the next read, unless the input is at its end.
 func runeBuffersApplyFuncAndTransfer(
 	inputBuffer *bytes.Buffer,
 	outputBuffer *bytes.Buffer,
-	transformFunc func(r rune) rune,
+	transformer *runeTransformer,
+	atEOF bool,
 ) error {
-	iterCount := 0
-	for inputRune, inputRuneSize, err := inputBuffer.ReadRune(); ; inputRune, inputRuneSize, err = inputBuffer.ReadRune() {
-		if errors.Is(err, io.EOF) { // The whole input buffer has been read so end.
-			inputBuffer.Reset()
-			break
-		}
-		if err != nil {
-			return err // Unexpected error has occurred.
-		}
-
-		if isInvalidRune(inputRune, inputRuneSize) {
-			if err = inputBuffer.UnreadRune(); err != nil {
-				return err // Unexpected error has occurred.
-			}
-			compactBuffer(inputBuffer)
-			if iterCount == 0 {
-				return ErrErroneousInitialRune
-			}
-			return ErrErroneousRune
-		}
-
-		transformedRune := transformFunc(inputRune)
-		if _, err = outputBuffer.WriteRune(transformedRune); err != nil {
-			return err
-		}
-		iterCount++
-	}
-	return nil
-}
-
-func isInvalidRune(r rune, size int) bool {
-	return r == '\uFFFD' && size == 1
+	output, consumed, err := transformer.transform(outputBuffer.AvailableBuffer(), inputBuffer.Bytes(), atEOF)
+	_, _ = outputBuffer.Write(output)
+	inputBuffer.Next(consumed)
+	compactBuffer(inputBuffer)
+	return err
 }
 
 func compactBuffer(inputBuffer *bytes.Buffer) {
@@ -132,9 +171,3 @@ func compactBuffer(inputBuffer *bytes.Buffer) {
 	inputBuffer.Reset()
 	inputBuffer.Write(unreadChunk)
 }
-
-var (
-	ErrUnableToTransformRune = errors.New("after two consecutive reads, could not transform rune")
-	ErrErroneousInitialRune  = errors.New("invalid initial rune has been read")
-	ErrErroneousRune         = errors.New("invalid rune has been read")
-)
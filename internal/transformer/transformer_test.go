@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"testing"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -41,7 +42,7 @@ func Test_runeBuffersApplyFuncAndTransfer_allRunesCorrectlyRead(t *testing.T) {
 	expectedOutputBuffer.WriteRune(expectedOutputRune)
 
 	// when
-	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformFunc)
+	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, newRuneTransformer(transformFunc, InvalidError), false)
 	// then
 	assert.NoError(t, err)
 	assert.Equal(t, expectedInputBuffer, inputBuffer)
@@ -58,17 +59,15 @@ func Test_runeBuffersApplyFuncAndTransfer_partialTransformationLastRuneNotComple
 
 	outputBuffer := new(bytes.Buffer)
 
-	expectedErr := ErrErroneousRune
-
 	expectedInputBuffer := new(bytes.Buffer)
 	expectedInputBuffer.Write(inputRuneBytesSlice)
 
 	expectedOutputBuffer := new(bytes.Buffer)
 	expectedOutputBuffer.WriteRune(expectedOutputRune)
 	// when
-	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformFunc)
+	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, newRuneTransformer(transformFunc, InvalidError), false)
 	// then
-	assert.Equal(t, expectedErr, err)
+	assert.NoError(t, err)
 	assert.Equal(t, expectedInputBuffer, inputBuffer)
 	assert.Equal(t, expectedOutputBuffer, outputBuffer)
 }
@@ -83,7 +82,7 @@ func Test_runeBuffersApplyFuncAndTransfer_partialTransformationLastRuneNotComple
 
 	outputBuffer := new(bytes.Buffer)
 
-	expectedErr := ErrErroneousRune
+	transformer := newRuneTransformer(transformFunc, InvalidError)
 
 	expectedInputBuffer := bytes.NewBuffer(make([]byte, 0, 64))
 
@@ -91,10 +90,11 @@ func Test_runeBuffersApplyFuncAndTransfer_partialTransformationLastRuneNotComple
 	expectedOutputBuffer.WriteRune(expectedOutputRune)
 	expectedOutputBuffer.WriteRune(expectedOutputRune)
 	// when & then
-	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformFunc)
-	assert.Equal(t, expectedErr, err)
+	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformer, false)
+	assert.NoError(t, err)
 	inputBuffer.WriteByte(136)
-	err = runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformFunc)
+	err = runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformer, false)
+	assert.NoError(t, err)
 	assert.Equal(t, expectedInputBuffer, inputBuffer)
 	assert.Equal(t, expectedOutputBuffer, outputBuffer)
 }
@@ -107,16 +107,14 @@ func Test_runeBuffersApplyFuncAndTransfer_initialRuneIncomplete(t *testing.T) {
 
 	outputBuffer := new(bytes.Buffer)
 
-	expectedErr := ErrErroneousInitialRune
-
 	expectedInputBuffer := new(bytes.Buffer)
 	expectedInputBuffer.Write(inputRuneBytesSlice)
 
 	expectedOutputBuffer := new(bytes.Buffer)
 	// when
-	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, transformFunc)
+	err := runeBuffersApplyFuncAndTransfer(inputBuffer, outputBuffer, newRuneTransformer(transformFunc, InvalidError), false)
 	// then
-	assert.Equal(t, expectedErr, err)
+	assert.NoError(t, err)
 	assert.Equal(t, expectedInputBuffer, inputBuffer)
 	assert.Equal(t, expectedOutputBuffer, outputBuffer)
 }
@@ -140,7 +138,7 @@ func Test_applyFuncAndTransfer_properTransfer(t *testing.T) {
 	expectedReaderSize := 0
 
 	// when
-	err := applyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, transformFunc)
+	err := ApplyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, transformFunc)
 
 	// then
 	assert.NoError(t, err)
@@ -169,10 +167,10 @@ func Test_applyFuncAndTransfer_lastRuneReadFails(t *testing.T) {
 	expectedReaderSize := 0
 
 	// when
-	err := applyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, transformFunc)
+	err := ApplyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, transformFunc)
 
 	// then
-	assert.Equal(t, ErrUnableToTransformRune, err)
+	assert.Equal(t, &ErrInvalidUTF8{int64(len(inputRuneBytes)), inputRuneBytes[:1]}, err)
 	assert.Equal(t, expectedInputBufferSize, inputBuffer.Len())
 	assert.Equal(t, expectedOutputBufferSize, outputBuffer.Len())
 	assert.Equal(t, expectedReaderSize, reader.Len())
@@ -194,15 +192,15 @@ func Test_applyFuncAndTransfer_consecutiveRuneReadFails(t *testing.T) {
 	inputBuffer := bytes.NewBuffer(make([]byte, 0, 2))
 	outputBuffer := new(bytes.Buffer)
 
-	expectedInputBufferSize := 4
+	expectedInputBufferSize := 3
 	expectedOutputBufferSize := 0
-	expectedReaderSize := 0
+	expectedReaderSize := 1
 
 	// when
-	err := applyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, transformFunc)
+	err := ApplyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, transformFunc)
 
 	// then
-	assert.Equal(t, ErrUnableToTransformRune, err)
+	assert.Equal(t, &ErrInvalidUTF8{int64(len(inputRuneBytes)), inputRuneBytes[:1]}, err)
 	assert.Equal(t, expectedInputBufferSize, inputBuffer.Len())
 	assert.Equal(t, expectedOutputBufferSize, outputBuffer.Len())
 	assert.Equal(t, expectedReaderSize, reader.Len())
@@ -259,3 +257,87 @@ func closeAndDeleteFile(file *os.File) {
 		panic(err)
 	}
 }
+
+func Test_applyFuncAndTransfer_positionKeptAcrossReads(t *testing.T) {
+	// given
+	inputRuneAmount := 8
+	reader := new(bytes.Buffer)
+	expectedWriter := bytes.NewBuffer(make([]byte, 0, 64))
+	for i := 0; i < inputRuneAmount; i++ {
+		reader.Write(inputRuneBytes)
+		expectedWriter.WriteRune(inputRune + rune(i))
+	}
+	writer := new(bytes.Buffer)
+
+	inputBuffer := bytes.NewBuffer(make([]byte, 0, 5))
+	outputBuffer := new(bytes.Buffer)
+
+	positionalFunc := WithPosition(func(position int, r rune) rune {
+		return r + rune(position)
+	})
+
+	// when
+	err := ApplyFuncAndTransfer(reader, writer, inputBuffer, outputBuffer, positionalFunc)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedWriter, writer)
+}
+
+func Test_applyFuncAndTransfer_roundTripOverAllScalarValues(t *testing.T) {
+	// given
+	var offset int32 = 0x1234
+	input := new(bytes.Buffer)
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if utf8.ValidRune(r) {
+			input.WriteRune(r)
+		}
+	}
+	expected := bytes.Clone(input.Bytes())
+	encoded := new(bytes.Buffer)
+	decoded := new(bytes.Buffer)
+
+	// when
+	encodeErr := ApplyFuncAndTransfer(input, encoded, bytes.NewBuffer(make([]byte, 0, ReadBufferSize)),
+		bytes.NewBuffer(make([]byte, 0, WriteBufferSize)), algorithms.NewOffsetRuneFunc(offset))
+	decodeErr := ApplyFuncAndTransfer(encoded, decoded, bytes.NewBuffer(make([]byte, 0, ReadBufferSize)),
+		bytes.NewBuffer(make([]byte, 0, WriteBufferSize)), algorithms.NewOffsetRuneFunc(-offset))
+
+	// then
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, decodeErr)
+	assert.True(t, bytes.Equal(expected, decoded.Bytes()))
+}
+
+func Test_closeFile_errorPropagated(t *testing.T) {
+	// given
+	file, err := os.CreateTemp(t.TempDir(), "close")
+	if err != nil {
+		panic(err)
+	}
+	if err = file.Close(); err != nil {
+		panic(err)
+	}
+	var resultErr error
+	// when
+	closeFile(file, &resultErr)
+	// then
+	assert.ErrorIs(t, resultErr, os.ErrClosed)
+}
+
+func Test_closeFile_earlierErrorKept(t *testing.T) {
+	// given
+	file, err := os.CreateTemp(t.TempDir(), "close")
+	if err != nil {
+		panic(err)
+	}
+	if err = file.Close(); err != nil {
+		panic(err)
+	}
+	earlierErr := &ErrInvalidUTF8{Offset: 1}
+	var resultErr error = earlierErr
+	// when
+	closeFile(file, &resultErr)
+	// then
+	assert.Equal(t, earlierErr, resultErr)
+}
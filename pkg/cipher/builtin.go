@@ -0,0 +1,231 @@
+package cipher
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+)
+
+// Names of the parameters shared by the built-in algorithms.
+const (
+	KeyParam        = "key"
+	AlphabetParam   = "alphabet"
+	PassphraseParam = "passphrase"
+	IterationsParam = "iterations"
+	DomainParam     = "domain"
+	BlockParam      = "block"
+)
+
+// blockParam is the parameter of the ciphers transforming the runes in blocks. It is not secret, as the
+// decoding of the container has to read it from the header.
+var blockParam = Param{
+	Name:        BlockParam,
+	Type:        IntType,
+	Description: "number of runes transposed together, the last block of the input may be shorter",
+	Default:     strconv.Itoa(DefaultBlockSize),
+	Min:         1,
+	Max:         MaxBlockSize,
+}
+
+func init() {
+	Register(Algorithm{
+		Name:        "aes-gcm",
+		Aliases:     []string{"aes"},
+		Description: "encrypts any data with AES-256-GCM in authenticated chunks, under the key derived from the passphrase",
+		Units:       []Unit{ByteUnit},
+		Params: []Param{
+			{Name: PassphraseParam, Type: StringType, Description: "passphrase to derive the key from", Required: true, Secret: true},
+			{
+				Name:        IterationsParam,
+				Type:        IntType,
+				Description: "PBKDF2 iteration count of the encoding, the decoding reads it from the data",
+				Default:     strconv.Itoa(DefaultAesGcmIterations),
+				Min:         1,
+				Max:         algorithms.MaxAesGcmIterations,
+			},
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewAesGcm(params.String(PassphraseParam), params.Int(IterationsParam))
+		},
+	})
+	Register(Algorithm{
+		Name:        "affine",
+		Description: "maps the letter of the index x within the alphabet onto the letter of the index a*x + b, leaving other runes untouched",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{
+				Name:        KeyParam,
+				Type:        StringType,
+				Description: "multiplier a, coprime with the alphabet size, and offset b separated with a comma, e.g. 5,8, or with a colon within a pipeline stage",
+				Required:    true,
+				Secret:      true,
+			},
+			{Name: AlphabetParam, Type: AlphabetType, Description: "alphabet to map within", Default: algorithms.LatinAlphabetsName},
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			key, err := ParseAffineKey(params.String(KeyParam))
+			if err != nil {
+				return nil, err
+			}
+			return NewAffine(key, params.Alphabets(AlphabetParam)...)
+		},
+	})
+	Register(Algorithm{
+		Name:        "atbash",
+		Description: "reverses the order of the letters of the alphabet, e.g. A and Z swap places, leaving other runes untouched",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{Name: AlphabetParam, Type: AlphabetType, Description: "alphabet to reverse", Default: algorithms.LatinAlphabetsName},
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewAtbash(params.Alphabets(AlphabetParam)...), nil
+		},
+	})
+	Register(Algorithm{
+		Name:        "caesar",
+		Aliases:     []string{"shift"},
+		Description: "shifts every rune by the key over the Unicode scalar values, every byte modulo 256, or only the runes of the alphabet",
+		Units:       []Unit{RuneUnit, ByteUnit},
+		Params: []Param{
+			{Name: KeyParam, Type: IntType, Description: "offset, negative to shift backwards", Required: true, Secret: true},
+			{Name: AlphabetParam, Type: AlphabetType, Description: "alphabet to rotate within, other runes are left untouched"},
+		},
+		New: newCaesarAlgorithm,
+	})
+	Register(Algorithm{
+		Name:        "columnar",
+		Description: "writes the runes row by row under the key and reads them column by column in the alphabetical order of the key",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{Name: KeyParam, Type: StringType, Description: "word of at least two runes, e.g. ZEBRAS", Required: true, Secret: true},
+			blockParam,
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewColumnar(params.String(KeyParam), params.Int(BlockParam))
+		},
+	})
+	Register(Algorithm{
+		Name:        "double-transposition",
+		Aliases:     []string{"double"},
+		Description: "applies the columnar transposition twice, with the two keys",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{Name: KeyParam, Type: StringType, Description: "two words separated with a comma, e.g. ZEBRAS,STRIPE, or with a colon within a pipeline stage", Required: true, Secret: true},
+			blockParam,
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewDoubleTransposition(params.String(KeyParam), params.Int(BlockParam))
+		},
+	})
+	Register(Algorithm{
+		Name:        "mirror",
+		Description: "reverses the order of the runes of the domain or of the alphabet, or of all the bytes",
+		Units:       []Unit{RuneUnit, ByteUnit},
+		Params: []Param{
+			{
+				Name:        DomainParam,
+				Type:        StringType,
+				Description: "runes to mirror, the others are rejected: " + mirrorDomainsString(),
+				Default:     string(ScalarDomain),
+			},
+			{Name: AlphabetParam, Type: AlphabetType, Description: "alphabet to mirror instead of the domain, other runes are rejected"},
+		},
+		New: newMirrorAlgorithm,
+	})
+	Register(Algorithm{
+		Name:        "railfence",
+		Aliases:     []string{"rail-fence"},
+		Description: "writes the runes in a zigzag over the rails and reads them rail by rail",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{Name: KeyParam, Type: IntType, Description: "number of rails, fewer than the block size", Required: true, Secret: true, Min: 2, Max: MaxBlockSize - 1},
+			blockParam,
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewRailFence(params.Int(KeyParam), params.Int(BlockParam))
+		},
+	})
+	Register(Algorithm{
+		Name:        "substitution",
+		Aliases:     []string{"monoalphabetic"},
+		Description: "substitutes the letters of the alphabet according to the key, leaving other runes untouched",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{
+				Name:        KeyParam,
+				Type:        StringType,
+				Description: "keyword followed by the remaining letters, e.g. ZEBRAS, or a full permutation of the alphabet",
+				Required:    true,
+				Secret:      true,
+			},
+			{Name: AlphabetParam, Type: AlphabetType, Description: "alphabets of the same size to substitute within", Default: algorithms.LatinAlphabetsName},
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewSubstitution(params.String(KeyParam), params.Alphabets(AlphabetParam)...)
+		},
+	})
+	Register(Algorithm{
+		Name:        "vigenere",
+		Aliases:     []string{"vigenère"},
+		Description: "shifts the Latin letters by the consecutive letters of the key",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{Name: KeyParam, Type: StringType, Description: "word made of Latin letters", Required: true, Secret: true},
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewVigenere(params.String(KeyParam))
+		},
+	})
+	Register(Algorithm{
+		Name:        "xor",
+		Description: "xors every byte with the key",
+		Units:       []Unit{ByteUnit},
+		Params: []Param{
+			{Name: KeyParam, Type: IntType, Description: "byte to xor with", Required: true, Secret: true, Min: 0, Max: math.MaxUint8},
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewXor(params.Int(KeyParam))
+		},
+	})
+}
+
+func newCaesarAlgorithm(unit Unit, params Params) (*Cipher, error) {
+	key := params.Int(KeyParam)
+	switch {
+	case unit == ByteUnit && params.Has(AlphabetParam):
+		return nil, &ErrUnsupportedParam{"caesar", AlphabetParam}
+	case unit == ByteUnit:
+		return NewByteCaesar(key), nil
+	case params.Has(AlphabetParam):
+		return NewAlphabetCaesar(key, params.Alphabets(AlphabetParam)...), nil
+	default:
+		return NewCaesar(key)
+	}
+}
+
+func newMirrorAlgorithm(unit Unit, params Params) (*Cipher, error) {
+	switch {
+	case unit == ByteUnit && params.Has(AlphabetParam):
+		return nil, &ErrUnsupportedParam{"mirror", AlphabetParam}
+	case unit == ByteUnit:
+		return NewByteMirror(), nil
+	case params.Has(AlphabetParam):
+		return NewAlphabetMirror(params.Alphabets(AlphabetParam)...), nil
+	}
+	domain := params.String(DomainParam)
+	mirror, err := NewDomainMirror(MirrorDomain(domain))
+	if err != nil {
+		return nil, &ErrInvalidParam{"mirror", DomainParam, domain, "expected one of: " + mirrorDomainsString()}
+	}
+	return mirror, nil
+}
+
+func mirrorDomainsString() string {
+	domains := make([]string, 0, len(algorithms.MirrorDomains))
+	for _, domain := range algorithms.MirrorDomains {
+		domains = append(domains, string(domain))
+	}
+	return strings.Join(domains, ", ")
+}
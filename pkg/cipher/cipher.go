@@ -0,0 +1,373 @@
+// Package cipher exposes the encoder-decoder ciphers and the streaming transformer they run on, so that
+// they can be used from other Go programs.
+package cipher
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+)
+
+// Cipher encodes and decodes streams either rune by rune or in blocks of runes, in which case the input has
+// to be valid UTF-8, byte by byte, or as a whole, in which case any input is accepted.
+type Cipher struct {
+	encodeFunc       func(position int, r rune) rune
+	decodeFunc       func(position int, r rune) rune
+	encodeBlockFunc  func(output []rune, block []rune) []rune
+	decodeBlockFunc  func(output []rune, block []rune) []rune
+	blockSize        int
+	encodeByteFunc   func(b byte) byte
+	decodeByteFunc   func(b byte) byte
+	encodeStreamFunc func(reader io.Reader, writer io.Writer, bufferSize int) error
+	decodeStreamFunc func(reader io.Reader, writer io.Writer) error
+	workers          int
+	bufferSize       int
+	invalidPolicy    InvalidPolicy
+}
+
+type (
+	Alphabet               = algorithms.Alphabet
+	ErrInvalidKey          = algorithms.ErrInvalidKey
+	InvalidPolicy          = transformer.InvalidPolicy
+	ErrInvalidUTF8         = transformer.ErrInvalidUTF8
+	ErrUntransformableRune = transformer.ErrUntransformableRune
+	MirrorDomain           = algorithms.MirrorDomain
+	AffineKey              = algorithms.AffineKey
+)
+
+// The policies of handling the invalid UTF-8 input of the ciphers operating on runes.
+const (
+	InvalidError       = transformer.InvalidError
+	InvalidReplace     = transformer.InvalidReplace
+	InvalidSkip        = transformer.InvalidSkip
+	InvalidPassthrough = transformer.InvalidPassthrough
+)
+
+// The domains of the mirror, the runes outside of which it rejects with ErrUntransformableRune.
+const (
+	ScalarDomain = algorithms.ScalarDomain
+	BMPDomain    = algorithms.BMPDomain
+	Latin1Domain = algorithms.Latin1Domain
+)
+
+// DefaultBlockSize is the number of runes the transposition ciphers reorder together, unless told otherwise.
+// The decoding has to use the block size of the encoding, so the container Header records it.
+const (
+	DefaultBlockSize = transformer.DefaultBlockSize
+	MaxBlockSize     = 16 * 1024 * 1024
+)
+
+// DefaultAesGcmIterations is the PBKDF2 iteration count recommended by OWASP for HMAC-SHA256.
+const DefaultAesGcmIterations = 600_000
+
+// ErrAuthenticationFailed is returned by the AES-GCM decoding of tampered data or with a wrong passphrase.
+var ErrAuthenticationFailed = algorithms.ErrAuthenticationFailed
+
+var (
+	LatinLowercase    = algorithms.LatinLowercase
+	LatinUppercase    = algorithms.LatinUppercase
+	CyrillicLowercase = algorithms.CyrillicLowercase
+	CyrillicUppercase = algorithms.CyrillicUppercase
+	GreekLowercase    = algorithms.GreekLowercase
+	GreekUppercase    = algorithms.GreekUppercase
+	Digits            = algorithms.Digits
+)
+
+// NewAlphabet creates an alphabet consisting of the given runes, in the given order.
+func NewAlphabet(letters string) (*Alphabet, error) {
+	return algorithms.NewAlphabet(letters)
+}
+
+// NewCaesar shifts the runes over the whole Unicode scalar value range. The key is reduced modulo the
+// number of the scalar values, so the multiples of that number are rejected, as they do not shift.
+func NewCaesar(key int) (*Cipher, error) {
+	offset := int32(key % algorithms.ScalarValues)
+	if offset == 0 {
+		reason := fmt.Sprintf("offset of a multiple of %d does not do anything", algorithms.ScalarValues)
+		return nil, &ErrInvalidKey{Key: strconv.Itoa(key), Reason: reason}
+	}
+	return NewRuneCipher(algorithms.NewOffsetRuneFunc(offset), algorithms.NewOffsetRuneFunc(-offset)), nil
+}
+
+// NewAlphabetCaesar rotates the runes within the first of the alphabets containing them, leaving the
+// other runes untouched.
+func NewAlphabetCaesar(key int, alphabets ...*Alphabet) *Cipher {
+	return NewRuneCipher(
+		algorithms.NewAlphabetOffsetRuneFunc(key, true, alphabets...),
+		algorithms.NewAlphabetOffsetRuneFunc(key, false, alphabets...),
+	)
+}
+
+// NewMirror mirrors the runes over the whole Unicode scalar value range, so it transforms any UTF-8 text.
+func NewMirror() *Cipher {
+	mirror, _ := NewDomainMirror(ScalarDomain)
+	return mirror
+}
+
+// NewDomainMirror mirrors the runes within the domain, rejecting the runes outside of it.
+func NewDomainMirror(domain MirrorDomain) (*Cipher, error) {
+	mirrorFunc, err := algorithms.NewMirrorRuneFunc(domain)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuneCipher(mirrorFunc, mirrorFunc), nil
+}
+
+// NewAlphabetMirror reverses the order of the runes within the first of the alphabets containing them,
+// rejecting the runes of none of them.
+func NewAlphabetMirror(alphabets ...*Alphabet) *Cipher {
+	mirrorFunc := algorithms.NewAlphabetMirrorRuneFunc(alphabets...)
+	return NewRuneCipher(mirrorFunc, mirrorFunc)
+}
+
+// ParseAffineKey parses the multiplier and the offset separated with the comma or the colon, e.g. 5,8.
+func ParseAffineKey(key string) (AffineKey, error) {
+	return algorithms.ParseAffineKey(key)
+}
+
+// NewAffine maps the rune of the index x within the first of the alphabets containing it onto the rune of
+// the index a*x + b, leaving the other runes untouched. The multiplier has to be coprime with the sizes
+// of the alphabets.
+func NewAffine(key AffineKey, alphabets ...*Alphabet) (*Cipher, error) {
+	encodeFunc, err := algorithms.NewAffineRuneFunc(key, true, alphabets...)
+	if err != nil {
+		return nil, err
+	}
+	decodeFunc, err := algorithms.NewAffineRuneFunc(key, false, alphabets...)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuneCipher(encodeFunc, decodeFunc), nil
+}
+
+// NewAtbash reverses the order of the runes within the first of the alphabets containing them, leaving
+// the other runes untouched. With LatinLowercase and LatinUppercase it is the classic Atbash.
+func NewAtbash(alphabets ...*Alphabet) *Cipher {
+	atbashFunc := algorithms.NewAtbashRuneFunc(alphabets...)
+	return NewRuneCipher(atbashFunc, atbashFunc)
+}
+
+// NewSubstitution substitutes the runes within the alphabets, which have to be of the same size, according
+// to the key being either a keyword, e.g. ZEBRAS for ZEBRASCDFGHIJKLMNOPQTUVWXY, or a full permutation of
+// the alphabet. The other runes are left untouched.
+func NewSubstitution(key string, alphabets ...*Alphabet) (*Cipher, error) {
+	encodeFunc, err := algorithms.NewSubstitutionRuneFunc(key, true, alphabets...)
+	if err != nil {
+		return nil, err
+	}
+	decodeFunc, err := algorithms.NewSubstitutionRuneFunc(key, false, alphabets...)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuneCipher(encodeFunc, decodeFunc), nil
+}
+
+// NewRailFence writes the runes of every block in a zigzag over the rails and reads them rail by rail.
+// There have to be fewer rails than the block size, as with as many rails as runes, they stay in place.
+func NewRailFence(rails int, blockSize int) (*Cipher, error) {
+	permutationFunc, err := algorithms.NewRailFencePermutationFunc(rails)
+	if err != nil {
+		return nil, err
+	}
+	railFence, err := newBlockCipher(permutationFunc, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	if rails >= blockSize {
+		reason := fmt.Sprintf("there have to be fewer rails than the block size: %d", blockSize)
+		return nil, &ErrInvalidKey{Key: strconv.Itoa(rails), Reason: reason}
+	}
+	return railFence, nil
+}
+
+// NewColumnar writes the runes of every block row by row under the runes of the key, and reads them column
+// by column in the alphabetical order of the key runes.
+func NewColumnar(key string, blockSize int) (*Cipher, error) {
+	permutationFunc, err := algorithms.NewColumnarPermutationFunc(key)
+	if err != nil {
+		return nil, err
+	}
+	return newBlockCipher(permutationFunc, blockSize)
+}
+
+// NewDoubleTransposition applies the columnar transposition twice, with the two keys separated with the
+// comma or the colon, e.g. ZEBRAS,STRIPE.
+func NewDoubleTransposition(keys string, blockSize int) (*Cipher, error) {
+	permutationFunc, err := algorithms.NewDoubleTranspositionPermutationFunc(keys)
+	if err != nil {
+		return nil, err
+	}
+	return newBlockCipher(permutationFunc, blockSize)
+}
+
+func NewVigenere(key string) (*Cipher, error) {
+	encodeFunc, err := algorithms.NewVigenereRuneFunc(key, true)
+	if err != nil {
+		return nil, err
+	}
+	decodeFunc, err := algorithms.NewVigenereRuneFunc(key, false)
+	if err != nil {
+		return nil, err
+	}
+	return NewPositionalRuneCipher(encodeFunc, decodeFunc), nil
+}
+
+// NewByteCaesar shifts the bytes modulo 256.
+func NewByteCaesar(key int) *Cipher {
+	// The key is reduced before it is negated, as negating math.MinInt overflows.
+	return NewByteCipher(algorithms.NewOffsetByteFunc(key), algorithms.NewOffsetByteFunc(-(key % (math.MaxUint8 + 1))))
+}
+
+func NewByteMirror() *Cipher {
+	return NewByteCipher(algorithms.GetMirrorByte, algorithms.GetMirrorByte)
+}
+
+func NewXor(key int) (*Cipher, error) {
+	xorFunc, err := algorithms.NewXorByteFunc(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewByteCipher(xorFunc, xorFunc), nil
+}
+
+// NewAesGcm encrypts with AES-256-GCM under the key derived from the passphrase with PBKDF2 of the given
+// iteration count. The data is sealed in authenticated chunks of the read buffer size, see WithBufferSize.
+func NewAesGcm(passphrase string, iterations int) (*Cipher, error) {
+	encodeStreamFunc, err := algorithms.NewAesGcmEncryptFunc(passphrase, iterations)
+	if err != nil {
+		return nil, err
+	}
+	decodeStreamFunc, err := algorithms.NewAesGcmDecryptFunc(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{encodeStreamFunc: encodeStreamFunc, decodeStreamFunc: decodeStreamFunc}, nil
+}
+
+// NewRuneCipher creates the cipher transforming the runes one by one with the given functions, so that
+// other packages can Register their own algorithms. The decode function has to invert the encode one.
+func NewRuneCipher(encodeFunc func(rune) rune, decodeFunc func(rune) rune) *Cipher {
+	return &Cipher{encodeFunc: ignorePosition(encodeFunc), decodeFunc: ignorePosition(decodeFunc)}
+}
+
+// NewPositionalRuneCipher works like NewRuneCipher, but the functions also receive the position of the rune
+// in the whole input, like the Vigenère ones.
+func NewPositionalRuneCipher(
+	encodeFunc func(position int, r rune) rune,
+	decodeFunc func(position int, r rune) rune,
+) *Cipher {
+	return &Cipher{encodeFunc: encodeFunc, decodeFunc: decodeFunc}
+}
+
+func newBlockCipher(permutationFunc algorithms.PermutationFunc, blockSize int) (*Cipher, error) {
+	if blockSize < 1 || blockSize > MaxBlockSize {
+		return nil, &ErrInvalidBlockSize{blockSize}
+	}
+	encodeBlockFunc, decodeBlockFunc := algorithms.NewTranspositionBlockFuncs(permutationFunc, blockSize)
+	return &Cipher{encodeBlockFunc: encodeBlockFunc, decodeBlockFunc: decodeBlockFunc, blockSize: blockSize}, nil
+}
+
+// NewByteCipher is the byte by byte counterpart of NewRuneCipher.
+func NewByteCipher(encodeFunc func(byte) byte, decodeFunc func(byte) byte) *Cipher {
+	return &Cipher{encodeByteFunc: encodeFunc, decodeByteFunc: decodeFunc}
+}
+
+func ignorePosition(transformFunc func(rune) rune) func(int, rune) rune {
+	return func(_ int, r rune) rune {
+		return transformFunc(r)
+	}
+}
+
+// WithWorkers returns a copy of the cipher, whose Encode and Decode split the input into chunks transformed
+// concurrently by the given number of workers, e.g. runtime.GOMAXPROCS(0). The ciphers transforming the
+// stream as a whole, like AES-GCM, or in blocks, like the transposition ones, keep transforming it sequentially.
+func (cipher *Cipher) WithWorkers(workers int) *Cipher {
+	parallelCipher := *cipher
+	parallelCipher.workers = workers
+	return &parallelCipher
+}
+
+// WithBufferSize returns a copy of the cipher reading the input in chunks of the given size, instead of
+// the default transformer.ReadBufferSize. The buffers are taken from a pool, so that the ciphers
+// transforming many small inputs do not allocate them each time, and the size is rounded up to the power
+// of two between the size of the longest rune and transformer.MaxBufferSize, so that there are only a few
+// pools whatever the sizes. AES-GCM seals the chunks of the given size instead, as long as it does not
+// exceed algorithms.MaxAesGcmChunkSize, and records it in the header, so the decoding does not depend on it.
+func (cipher *Cipher) WithBufferSize(bufferSize int) *Cipher {
+	resizedCipher := *cipher
+	resizedCipher.bufferSize = bufferSize
+	return &resizedCipher
+}
+
+// WithInvalidPolicy returns a copy of the cipher handling the invalid UTF-8 input according to the policy,
+// instead of failing with ErrInvalidUTF8. The ciphers operating on bytes accept any input anyway.
+func (cipher *Cipher) WithInvalidPolicy(policy InvalidPolicy) *Cipher {
+	tolerantCipher := *cipher
+	tolerantCipher.invalidPolicy = policy
+	return &tolerantCipher
+}
+
+// Encode reads the reader until io.EOF and writes the encoded data to the writer.
+func (cipher *Cipher) Encode(reader io.Reader, writer io.Writer) error {
+	if cipher.encodeStreamFunc != nil {
+		return cipher.encodeStreamFunc(reader, writer, cipher.readBufferSize())
+	}
+	if cipher.encodeBlockFunc != nil {
+		return transformer.ApplyBlockFuncAndTransfer(
+			reader, writer, cipher.readBufferSize(), cipher.blockSize, cipher.encodeBlockFunc, cipher.invalidPolicy,
+		)
+	}
+	return cipher.transfer(reader, writer, cipher.encodeFunc, cipher.encodeByteFunc)
+}
+
+// Decode reads the reader until io.EOF and writes the decoded data to the writer.
+func (cipher *Cipher) Decode(reader io.Reader, writer io.Writer) error {
+	if cipher.decodeStreamFunc != nil {
+		return cipher.decodeStreamFunc(reader, writer)
+	}
+	if cipher.decodeBlockFunc != nil {
+		return transformer.ApplyBlockFuncAndTransfer(
+			reader, writer, cipher.readBufferSize(), cipher.blockSize, cipher.decodeBlockFunc, cipher.invalidPolicy,
+		)
+	}
+	return cipher.transfer(reader, writer, cipher.decodeFunc, cipher.decodeByteFunc)
+}
+
+func (cipher *Cipher) transfer(
+	reader io.Reader,
+	writer io.Writer,
+	transformFunc func(position int, r rune) rune,
+	transformByteFunc func(b byte) byte,
+) error {
+	bufferSize := cipher.readBufferSize()
+	switch {
+	case cipher.workers > 1 && transformByteFunc != nil:
+		return transformer.ApplyByteFuncInParallel(reader, writer, transformByteFunc, cipher.workers)
+	case cipher.workers > 1:
+		return transformer.ApplyPositionalFuncInParallel(reader, writer, transformFunc, cipher.invalidPolicy, cipher.workers)
+	case transformByteFunc != nil:
+		return transformer.PooledApplyByteFuncAndTransfer(reader, writer, bufferSize, transformByteFunc)
+	default:
+		return transformer.PooledApplyPositionalFuncAndTransfer(reader, writer, bufferSize, transformFunc, cipher.invalidPolicy)
+	}
+}
+
+// readBufferSize defaults to transformer.ReadBufferSize, unless set with WithBufferSize.
+func (cipher *Cipher) readBufferSize() int {
+	if cipher.bufferSize == 0 {
+		return transformer.ReadBufferSize
+	}
+	return cipher.bufferSize
+}
+
+type ErrInvalidBlockSize struct {
+	BlockSize int
+}
+
+func (e *ErrInvalidBlockSize) Error() string {
+	return fmt.Sprintf("invalid block size: %d, expected an integer within 1-%d", e.BlockSize, MaxBlockSize)
+}
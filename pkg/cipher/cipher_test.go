@@ -0,0 +1,313 @@
+package cipher
+
+import (
+	"bytes"
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+const plaintext = "Hello, World! Zażółć gęślą jaźń ✈"
+
+func newTestCiphers(t testing.TB) map[string]*Cipher {
+	caesar, err := NewCaesar(1234)
+	assert.NoError(t, err)
+	vigenere, err := NewVigenere("lemon")
+	assert.NoError(t, err)
+	xor, err := NewXor(0x5A)
+	assert.NoError(t, err)
+	affine, err := NewAffine(AffineKey{A: 5, B: 8}, LatinLowercase, LatinUppercase)
+	assert.NoError(t, err)
+	substitution, err := NewSubstitution("zebras", LatinLowercase, LatinUppercase)
+	assert.NoError(t, err)
+	railFence, err := NewRailFence(3, 7)
+	assert.NoError(t, err)
+	columnar, err := NewColumnar("zebras", DefaultBlockSize)
+	assert.NoError(t, err)
+	doubleTransposition, err := NewDoubleTransposition("zebras:stripe", 1000)
+	assert.NoError(t, err)
+	aesGcm, err := NewAesGcm("correct horse", 16)
+	assert.NoError(t, err)
+	return map[string]*Cipher{
+		"caesar":          caesar,
+		"alphabet caesar": NewAlphabetCaesar(13, LatinLowercase, LatinUppercase),
+		"affine":          affine,
+		"atbash":          NewAtbash(LatinLowercase, LatinUppercase),
+		"substitution":    substitution,
+		"rail fence":      railFence,
+		"columnar":        columnar,
+		"double":          doubleTransposition,
+		"vigenere":        vigenere,
+		"byte caesar":     NewByteCaesar(100),
+		"mirror":          NewMirror(),
+		"byte mirror":     NewByteMirror(),
+		"xor":             xor,
+		"aes-gcm":         aesGcm,
+	}
+}
+
+func Test_Cipher_roundTrip(t *testing.T) {
+	for name, cipher := range newTestCiphers(t) {
+		// given
+		encoded := new(bytes.Buffer)
+		decoded := new(bytes.Buffer)
+		// when
+		encodeErr := cipher.Encode(strings.NewReader(plaintext), encoded)
+		decodeErr := cipher.Decode(bytes.NewReader(encoded.Bytes()), decoded)
+		// then
+		assert.NoError(t, encodeErr, name)
+		assert.NoError(t, decodeErr, name)
+		assert.NotEqual(t, plaintext, encoded.String(), name)
+		assert.Equal(t, plaintext, decoded.String(), name)
+	}
+}
+
+func Test_NewAlphabetCaesar_rot13(t *testing.T) {
+	// given
+	cipher := NewAlphabetCaesar(13, LatinLowercase, LatinUppercase)
+	encoded := new(bytes.Buffer)
+	expected := "Uryyb, Jbeyq!"
+	// when
+	err := cipher.Encode(strings.NewReader("Hello, World!"), encoded)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, encoded.String())
+}
+
+func Test_NewAtbash_alphabets(t *testing.T) {
+	// given
+	cipher := NewAtbash(LatinLowercase, LatinUppercase, GreekLowercase, CyrillicUppercase, Digits)
+	encoded := new(bytes.Buffer)
+	expected := "Svool, Dliow! ωψ ЯЮ 9876"
+	// when
+	err := cipher.Encode(strings.NewReader("Hello, World! αβ АБ 0123"), encoded)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, encoded.String())
+}
+
+func Test_NewCaesar_zeroKey(t *testing.T) {
+	// given
+	keys := []int{0, algorithms.ScalarValues, -3 * algorithms.ScalarValues}
+	for _, key := range keys {
+		// when
+		cipher, err := NewCaesar(key)
+		// then
+		assert.Nil(t, cipher, key)
+		assert.IsType(t, &ErrInvalidKey{}, err, key)
+	}
+}
+
+func Test_NewCaesar_extremeKeys(t *testing.T) {
+	// given
+	keys := []int{1 << 32, math.MinInt32, math.MaxInt, math.MinInt}
+	for _, key := range keys {
+		cipher, err := NewCaesar(key)
+		assert.NoError(t, err, key)
+		encoded := new(bytes.Buffer)
+		decoded := new(bytes.Buffer)
+		// when
+		encodeErr := cipher.Encode(strings.NewReader(plaintext), encoded)
+		decodeErr := cipher.Decode(encoded, decoded)
+		// then
+		assert.NoError(t, encodeErr, key)
+		assert.NoError(t, decodeErr, key)
+		assert.Equal(t, plaintext, decoded.String(), key)
+	}
+}
+
+func Test_NewDomainMirror_untransformableRune(t *testing.T) {
+	// given
+	cipher, err := NewDomainMirror(Latin1Domain)
+	assert.NoError(t, err)
+	encoded := new(bytes.Buffer)
+	// when
+	err = cipher.Encode(strings.NewReader(plaintext), encoded)
+	// then
+	assert.Equal(t, &ErrUntransformableRune{Offset: 16, Rune: 'ż'}, err)
+}
+
+func Test_NewDomainMirror_unknownDomain(t *testing.T) {
+	// when
+	cipher, err := NewDomainMirror("ascii")
+	// then
+	assert.Nil(t, cipher)
+	assert.Error(t, err)
+}
+
+func Test_newMirrorAlgorithm(t *testing.T) {
+	// given
+	algorithm, _ := Lookup("mirror")
+	units := []Unit{RuneUnit, RuneUnit, ByteUnit}
+	rawParams := []map[string]string{
+		{AlphabetParam: "latin"},
+		{DomainParam: "ascii"},
+		{AlphabetParam: "latin"},
+	}
+	expectedEncoded := []string{"SvoolDliow", "", ""}
+	expectedErrs := []error{
+		nil,
+		&ErrInvalidParam{"mirror", DomainParam, "ascii", "expected one of: scalar, bmp, latin1"},
+		&ErrUnsupportedParam{"mirror", AlphabetParam},
+	}
+	for i, unit := range units {
+		encoded := new(bytes.Buffer)
+		// when
+		cipher, err := algorithm.NewCipher(unit, rawParams[i])
+		// then
+		assert.Equal(t, expectedErrs[i], err, rawParams[i])
+		if err == nil {
+			assert.NoError(t, cipher.Encode(strings.NewReader("HelloWorld"), encoded))
+		}
+		assert.Equal(t, expectedEncoded[i], encoded.String(), rawParams[i])
+	}
+}
+
+func Test_NewByteCaesar_extremeKeys(t *testing.T) {
+	// given
+	keys := []int{math.MaxInt, math.MinInt, math.MinInt + 1}
+	for _, key := range keys {
+		cipher := NewByteCaesar(key)
+		encoded := new(bytes.Buffer)
+		decoded := new(bytes.Buffer)
+		// when
+		encodeErr := cipher.Encode(strings.NewReader(plaintext), encoded)
+		decodeErr := cipher.Decode(encoded, decoded)
+		// then
+		assert.NoError(t, encodeErr, key)
+		assert.NoError(t, decodeErr, key)
+		assert.Equal(t, plaintext, decoded.String(), key)
+	}
+}
+
+func Test_NewRailFence(t *testing.T) {
+	// given
+	cipher, err := NewRailFence(3, DefaultBlockSize)
+	assert.NoError(t, err)
+	encoded := new(bytes.Buffer)
+	expected := "WECRLTEERDSOEEFEAOCAIVDEN"
+	// when
+	err = cipher.Encode(strings.NewReader("WEAREDISCOVEREDFLEEATONCE"), encoded)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expected, encoded.String())
+}
+
+func Test_NewRailFence_railsOfBlockSize(t *testing.T) {
+	// given
+	rails := []int{7, 8, 1 << 40}
+	for _, railCount := range rails {
+		// when
+		cipher, err := NewRailFence(railCount, 7)
+		// then
+		assert.Nil(t, cipher, railCount)
+		assert.IsType(t, &ErrInvalidKey{}, err, railCount)
+	}
+}
+
+func Test_NewColumnar_invalidBlockSize(t *testing.T) {
+	// given
+	blockSizes := []int{0, MaxBlockSize + 1}
+	for _, blockSize := range blockSizes {
+		// when
+		cipher, err := NewColumnar("zebras", blockSize)
+		// then
+		assert.Nil(t, cipher)
+		assert.Equal(t, &ErrInvalidBlockSize{blockSize}, err)
+	}
+}
+
+func Test_NewAesGcm_wrongPassphrase(t *testing.T) {
+	// given
+	aesGcm, _ := NewAesGcm("correct horse", 16)
+	wrongAesGcm, _ := NewAesGcm("wrong horse", 16)
+	encoded := new(bytes.Buffer)
+	decoded := new(bytes.Buffer)
+	assert.NoError(t, aesGcm.Encode(strings.NewReader(plaintext), encoded))
+	// when
+	err := wrongAesGcm.Decode(encoded, decoded)
+	// then
+	assert.Equal(t, ErrAuthenticationFailed, err)
+	assert.Zero(t, decoded.Len())
+}
+
+func Test_NewAesGcm_WithBufferSize(t *testing.T) {
+	// given
+	aesGcm, _ := NewAesGcm("correct horse", 16)
+	encoded := new(bytes.Buffer)
+	smallChunksEncoded := new(bytes.Buffer)
+	decoded := new(bytes.Buffer)
+	// when
+	err := aesGcm.Encode(strings.NewReader(plaintext), encoded)
+	smallChunksErr := aesGcm.WithBufferSize(4).Encode(strings.NewReader(plaintext), smallChunksEncoded)
+	decodeErr := aesGcm.Decode(bytes.NewReader(smallChunksEncoded.Bytes()), decoded)
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, smallChunksErr)
+	assert.NoError(t, decodeErr)
+	assert.Greater(t, smallChunksEncoded.Len(), encoded.Len())
+	assert.Equal(t, plaintext, decoded.String())
+}
+
+func Test_Cipher_WithWorkers(t *testing.T) {
+	// given
+	input := strings.Repeat(plaintext, 100_000)
+	for name, cipher := range newTestCiphers(t) {
+		expected := new(bytes.Buffer)
+		assert.NoError(t, cipher.Encode(strings.NewReader(input), expected), name)
+		encoded := new(bytes.Buffer)
+		decoded := new(bytes.Buffer)
+		parallelCipher := cipher.WithWorkers(4)
+		// when
+		encodeErr := parallelCipher.Encode(strings.NewReader(input), encoded)
+		decodeErr := parallelCipher.Decode(bytes.NewReader(encoded.Bytes()), decoded)
+		// then
+		assert.NoError(t, encodeErr, name)
+		assert.NoError(t, decodeErr, name)
+		if name != "aes-gcm" { // Its salt and so its ciphertext differ each time.
+			assert.Equal(t, expected.Len(), encoded.Len(), name)
+			assert.True(t, bytes.Equal(expected.Bytes(), encoded.Bytes()), name)
+		}
+		assert.True(t, input == decoded.String(), name)
+	}
+}
+
+func Test_Cipher_WithInvalidPolicy(t *testing.T) {
+	// given
+	input := "a\xFFb"
+	cipher, err := NewCaesar(1)
+	assert.NoError(t, err)
+	policies := []InvalidPolicy{InvalidError, InvalidReplace, InvalidSkip, InvalidPassthrough}
+	expectedOutputs := []string{"b", "b\uFFFEc", "bc", "b\xFFc"}
+	expectedErrs := []error{&ErrInvalidUTF8{Offset: 1, Bytes: []byte{0xFF}}, nil, nil, nil}
+	for i, policy := range policies {
+		for _, workers := range []int{0, 4} {
+			encoded := new(bytes.Buffer)
+			// when
+			err = cipher.WithInvalidPolicy(policy).WithWorkers(workers).Encode(strings.NewReader(input), encoded)
+			// then
+			assert.Equal(t, expectedErrs[i], err, policy)
+			assert.Equal(t, expectedOutputs[i], encoded.String(), policy)
+		}
+	}
+}
+
+func BenchmarkCipher_Encode(b *testing.B) {
+	input := strings.Repeat(plaintext, 10_000)
+	reader := strings.NewReader(input)
+	for name, cipher := range newTestCiphers(b) {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for range b.N {
+				reader.Reset(input)
+				if err := cipher.Encode(reader, io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package cipher
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+)
+
+// The container starts with the magic number, the format version, the length of the JSON encoded Header,
+// the Header itself and the CRC32 of all the preceding bytes. The encoded data follows, and the SHA-256
+// of the plaintext closes the container, as it is known only once the whole plaintext has been read. The
+// ciphers authenticating the data themselves, like AES-GCM, leave the digest out, as it would let anyone
+// confirm a guessed plaintext.
+var containerMagic = []byte("ENCDEC")
+
+const (
+	ContainerVersion   = 1
+	maxHeaderSize      = 1 << 16
+	containerTrailSize = sha256.Size
+)
+
+// Header describes how the container data has been encoded, without any of the secret parameters.
+type Header struct {
+	Unit       Unit              `json:"unit"`
+	Algorithms []HeaderAlgorithm `json:"algorithms"`
+}
+
+// HeaderAlgorithm is a stage of the pipeline the data has been encoded with.
+type HeaderAlgorithm struct {
+	Name   string            `json:"name"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// EncodeContainer works like Encode, but wraps the encoded data in the container described by the header.
+func (cipher *Cipher) EncodeContainer(reader io.Reader, writer io.Writer, header Header) error {
+	if err := writeHeader(writer, header); err != nil {
+		return err
+	}
+	if cipher.isAuthenticated() {
+		return cipher.Encode(reader, writer)
+	}
+	plaintextHash := sha256.New()
+	if err := cipher.Encode(io.TeeReader(reader, plaintextHash), writer); err != nil {
+		return err
+	}
+	_, err := writer.Write(plaintextHash.Sum(nil))
+	return err
+}
+
+func writeHeader(writer io.Writer, header Header) error {
+	headerJson, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	buffer := bytes.NewBuffer(make([]byte, 0, len(containerMagic)+9+len(headerJson)))
+	buffer.Write(containerMagic)
+	buffer.WriteByte(ContainerVersion)
+	_ = binary.Write(buffer, binary.BigEndian, uint32(len(headerJson)))
+	buffer.Write(headerJson)
+	_ = binary.Write(buffer, binary.BigEndian, crc32.ChecksumIEEE(buffer.Bytes()))
+	_, err = writer.Write(buffer.Bytes())
+	return err
+}
+
+// ReadHeader reads the header of the container. When the reader does not start with the magic number, it
+// returns ErrNotContainer without consuming anything, so that the reader can be decoded with Decode.
+func ReadHeader(reader *bufio.Reader) (Header, error) {
+	magic, err := reader.Peek(len(containerMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Header{}, err
+	}
+	if !bytes.Equal(magic, containerMagic) {
+		return Header{}, ErrNotContainer
+	}
+	checksum := crc32.NewIEEE()
+	teeReader := io.TeeReader(reader, checksum)
+	prefix := make([]byte, len(containerMagic)+5)
+	if _, err = io.ReadFull(teeReader, prefix); err != nil {
+		return Header{}, ErrCorruptedHeader
+	}
+	if version := prefix[len(containerMagic)]; version != ContainerVersion {
+		return Header{}, &ErrUnsupportedVersion{version}
+	}
+	headerSize := binary.BigEndian.Uint32(prefix[len(containerMagic)+1:])
+	if headerSize > maxHeaderSize {
+		return Header{}, ErrCorruptedHeader
+	}
+	headerJson := make([]byte, headerSize)
+	if _, err = io.ReadFull(teeReader, headerJson); err != nil {
+		return Header{}, ErrCorruptedHeader
+	}
+	var expectedChecksum uint32
+	if err = binary.Read(reader, binary.BigEndian, &expectedChecksum); err != nil || expectedChecksum != checksum.Sum32() {
+		return Header{}, ErrCorruptedHeader
+	}
+	var header Header
+	if err = json.Unmarshal(headerJson, &header); err != nil {
+		return Header{}, ErrCorruptedHeader
+	}
+	return header, nil
+}
+
+// DecodeContainer decodes the data following the header read with ReadHeader. The decoded data is written
+// as it is streamed, so it is only once the whole of it has been written that ErrWrongKeyOrCorrupted tells
+// that it does not match the plaintext it has been encoded from.
+func (cipher *Cipher) DecodeContainer(reader io.Reader, writer io.Writer) error {
+	if cipher.isAuthenticated() {
+		return cipher.Decode(reader, writer)
+	}
+	dataReader := newTrailingReader(reader, containerTrailSize)
+	plaintextHash := sha256.New()
+	if err := cipher.Decode(dataReader, io.MultiWriter(writer, plaintextHash)); err != nil {
+		return err
+	}
+	if !bytes.Equal(dataReader.trail, plaintextHash.Sum(nil)) {
+		return ErrWrongKeyOrCorrupted
+	}
+	return nil
+}
+
+// isAuthenticated tells whether the cipher detects the wrong key and the corrupted data on its own.
+func (cipher *Cipher) isAuthenticated() bool {
+	return cipher.decodeStreamFunc != nil
+}
+
+// trailingReader holds back the last trailSize bytes of the reader, which are left in the trail at io.EOF.
+type trailingReader struct {
+	reader    io.Reader
+	trailSize int
+	trail     []byte
+	chunk     []byte
+	eof       bool
+}
+
+func newTrailingReader(reader io.Reader, trailSize int) *trailingReader {
+	return &trailingReader{
+		reader:    reader,
+		trailSize: trailSize,
+		trail:     make([]byte, 0, trailSize+transformer.ReadBufferSize),
+		chunk:     make([]byte, transformer.ReadBufferSize),
+	}
+}
+
+func (trailingReader *trailingReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for !trailingReader.eof && len(trailingReader.trail) <= trailingReader.trailSize {
+		n, err := trailingReader.reader.Read(trailingReader.chunk)
+		trailingReader.trail = append(trailingReader.trail, trailingReader.chunk[:n]...)
+		if errors.Is(err, io.EOF) {
+			trailingReader.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	released := max(len(trailingReader.trail)-trailingReader.trailSize, 0)
+	n := copy(p, trailingReader.trail[:released])
+	trailingReader.trail = append(trailingReader.trail[:0], trailingReader.trail[n:]...)
+	if n == 0 && trailingReader.eof {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+type ErrUnsupportedVersion struct {
+	Version byte
+}
+
+func (e *ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("unsupported container version: %d, the supported version is: %d", e.Version, ContainerVersion)
+}
+
+var (
+	ErrNotContainer        = errors.New("input is not a container")
+	ErrCorruptedHeader     = errors.New("container header is corrupted")
+	ErrWrongKeyOrCorrupted = errors.New("wrong key or corrupted file")
+)
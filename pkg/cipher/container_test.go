@@ -0,0 +1,120 @@
+package cipher
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var testHeader = Header{
+	Unit:       RuneUnit,
+	Algorithms: []HeaderAlgorithm{{Name: "caesar", Params: map[string]string{"alphabet": "latin"}}},
+}
+
+func encodeTestContainer(t *testing.T, cipher *Cipher) []byte {
+	container := new(bytes.Buffer)
+	assert.NoError(t, cipher.EncodeContainer(strings.NewReader(plaintext), container, testHeader))
+	return container.Bytes()
+}
+
+func Test_Cipher_EncodeContainer_roundTrip(t *testing.T) {
+	// given
+	cipher := NewAlphabetCaesar(3, LatinLowercase, LatinUppercase)
+	reader := bufio.NewReader(iotest.OneByteReader(bytes.NewReader(encodeTestContainer(t, cipher))))
+	decoded := new(bytes.Buffer)
+	// when
+	header, headerErr := ReadHeader(reader)
+	decodeErr := cipher.DecodeContainer(reader, decoded)
+	// then
+	assert.NoError(t, headerErr)
+	assert.NoError(t, decodeErr)
+	assert.Equal(t, testHeader, header)
+	assert.Equal(t, plaintext, decoded.String())
+}
+
+func Test_Cipher_DecodeContainer_wrongKey(t *testing.T) {
+	// given
+	reader := bufio.NewReader(bytes.NewReader(encodeTestContainer(t, NewAlphabetCaesar(3, LatinLowercase))))
+	_, _ = ReadHeader(reader)
+	// when
+	err := NewAlphabetCaesar(4, LatinLowercase).DecodeContainer(reader, io.Discard)
+	// then
+	assert.Equal(t, ErrWrongKeyOrCorrupted, err)
+}
+
+func Test_Cipher_EncodeContainer_authenticatedWithoutDigest(t *testing.T) {
+	// given
+	aesGcm, _ := NewAesGcm("correct horse", 16)
+	plaintextDigest := sha256.Sum256([]byte(plaintext))
+	// when
+	container := encodeTestContainer(t, aesGcm)
+	// then
+	assert.NotContains(t, string(container), string(plaintextDigest[:]))
+	reader := bufio.NewReader(bytes.NewReader(container))
+	_, headerErr := ReadHeader(reader)
+	decoded := new(bytes.Buffer)
+	assert.NoError(t, headerErr)
+	assert.NoError(t, aesGcm.DecodeContainer(reader, decoded))
+	assert.Equal(t, plaintext, decoded.String())
+}
+
+func Test_ReadHeader_errors(t *testing.T) {
+	// given
+	container := encodeTestContainer(t, NewByteMirror())
+	otherVersion := bytes.Clone(container)
+	otherVersion[len(containerMagic)] = ContainerVersion + 1
+	corrupted := bytes.Clone(container)
+	corrupted[len(containerMagic)+7]++
+	inputs := [][]byte{
+		[]byte("plain"),
+		{},
+		otherVersion,
+		corrupted,
+		container[:len(containerMagic)+7],
+	}
+	expectedErrs := []error{
+		ErrNotContainer,
+		ErrNotContainer,
+		&ErrUnsupportedVersion{ContainerVersion + 1},
+		ErrCorruptedHeader,
+		ErrCorruptedHeader,
+	}
+	for i, input := range inputs {
+		// when
+		_, err := ReadHeader(bufio.NewReader(bytes.NewReader(input)))
+		// then
+		assert.Equal(t, expectedErrs[i], err, i)
+	}
+}
+
+func Test_ReadHeader_notContainerUnconsumed(t *testing.T) {
+	// given
+	reader := bufio.NewReader(strings.NewReader(plaintext))
+	// when
+	_, err := ReadHeader(reader)
+	rest, _ := io.ReadAll(reader)
+	// then
+	assert.Equal(t, ErrNotContainer, err)
+	assert.Equal(t, plaintext, string(rest))
+}
+
+func Test_trailingReader(t *testing.T) {
+	// given
+	inputs := []string{"", "ab", "abcd", "abcdefgh"}
+	expectedData := []string{"", "", "", "abcd"}
+	expectedTrails := []string{"", "ab", "abcd", "efgh"}
+	for i, input := range inputs {
+		reader := newTrailingReader(iotest.HalfReader(strings.NewReader(input)), 4)
+		// when
+		data, err := io.ReadAll(reader)
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, expectedData[i], string(data), input)
+		assert.Equal(t, expectedTrails[i], string(reader.trail), input)
+	}
+}
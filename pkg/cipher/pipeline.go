@@ -0,0 +1,88 @@
+package cipher
+
+import (
+	"errors"
+	"slices"
+)
+
+// NewPipeline chains the ciphers into one, which encodes with them in the given order and decodes with them
+// in the reverse order, in a single streaming pass. All the ciphers have to operate on the same unit, and
+// the ciphers transforming the stream as a whole, like AES-GCM, or in blocks, like the transposition ones,
+// cannot be chained.
+func NewPipeline(ciphers ...*Cipher) (*Cipher, error) {
+	if len(ciphers) == 0 {
+		return nil, ErrEmptyPipeline
+	}
+	if len(ciphers) == 1 {
+		return ciphers[0], nil
+	}
+	unit := ciphers[0].Unit()
+	for _, cipher := range ciphers {
+		if cipher.encodeStreamFunc != nil {
+			return nil, ErrChainedStreamCipher
+		}
+		if cipher.encodeBlockFunc != nil {
+			return nil, ErrChainedBlockCipher
+		}
+		if cipher.Unit() != unit {
+			return nil, ErrMixedUnits
+		}
+	}
+	reversed := slices.Clone(ciphers)
+	slices.Reverse(reversed)
+	if unit == ByteUnit {
+		return NewByteCipher(chainByteFuncs(ciphers, encodeByteFunc), chainByteFuncs(reversed, decodeByteFunc)), nil
+	}
+	return &Cipher{encodeFunc: chainFuncs(ciphers, encodeFunc), decodeFunc: chainFuncs(reversed, decodeFunc)}, nil
+}
+
+// Unit tells whether the cipher operates on runes or on bytes, the latter being the case of the ciphers
+// transforming the stream as a whole.
+func (cipher *Cipher) Unit() Unit {
+	if cipher.encodeByteFunc != nil || cipher.encodeStreamFunc != nil {
+		return ByteUnit
+	}
+	return RuneUnit
+}
+
+func encodeFunc(cipher *Cipher) func(int, rune) rune { return cipher.encodeFunc }
+
+func decodeFunc(cipher *Cipher) func(int, rune) rune { return cipher.decodeFunc }
+
+func encodeByteFunc(cipher *Cipher) func(byte) byte { return cipher.encodeByteFunc }
+
+func decodeByteFunc(cipher *Cipher) func(byte) byte { return cipher.decodeByteFunc }
+
+// chainFuncs passes the same position to every function, since each of them maps a rune to a single rune.
+func chainFuncs(ciphers []*Cipher, getFunc func(*Cipher) func(int, rune) rune) func(int, rune) rune {
+	transformFuncs := make([]func(int, rune) rune, 0, len(ciphers))
+	for _, cipher := range ciphers {
+		transformFuncs = append(transformFuncs, getFunc(cipher))
+	}
+	return func(position int, r rune) rune {
+		for _, transformFunc := range transformFuncs {
+			r = transformFunc(position, r)
+		}
+		return r
+	}
+}
+
+func chainByteFuncs(ciphers []*Cipher, getFunc func(*Cipher) func(byte) byte) func(byte) byte {
+	transformFuncs := make([]func(byte) byte, 0, len(ciphers))
+	for _, cipher := range ciphers {
+		transformFuncs = append(transformFuncs, getFunc(cipher))
+	}
+	return func(b byte) byte {
+		for _, transformFunc := range transformFuncs {
+			b = transformFunc(b)
+		}
+		return b
+	}
+}
+
+var (
+	ErrEmptyPipeline       = errors.New("pipeline has no ciphers")
+	ErrMixedUnits          = errors.New("pipeline mixes rune and byte ciphers")
+	ErrChainedStreamCipher = errors.New("cipher transforming the stream as a whole cannot be chained in a pipeline")
+	ErrChainedBlockCipher  = errors.New("cipher transforming the stream in blocks cannot be chained in a pipeline")
+)
@@ -0,0 +1,69 @@
+package cipher
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_NewPipeline_roundTrip(t *testing.T) {
+	// given
+	caesar, _ := NewCaesar(3)
+	vigenere, _ := NewVigenere("lemon")
+	xor, _ := NewXor(0x5A)
+	pipelines := map[string][]*Cipher{
+		"rune": {caesar, NewMirror(), vigenere},
+		"byte": {NewByteCaesar(7), NewByteMirror(), xor},
+	}
+	// the mirror of runes is limited to Latin-1
+	inputs := map[string]string{
+		"rune": "Hello, World! Ça va, señor?",
+		"byte": plaintext,
+	}
+	for name, ciphers := range pipelines {
+		pipeline, err := NewPipeline(ciphers...)
+		assert.NoError(t, err, name)
+		encoded := new(bytes.Buffer)
+		decoded := new(bytes.Buffer)
+		// when
+		encodeErr := pipeline.Encode(strings.NewReader(inputs[name]), encoded)
+		decodeErr := pipeline.Decode(bytes.NewReader(encoded.Bytes()), decoded)
+		// then
+		assert.NoError(t, encodeErr, name)
+		assert.NoError(t, decodeErr, name)
+		assert.Equal(t, inputs[name], decoded.String(), name)
+	}
+}
+
+func Test_NewPipeline_order(t *testing.T) {
+	// given
+	caesar, _ := NewCaesar(1)
+	vigenere, _ := NewVigenere("ab")
+	pipeline, _ := NewPipeline(caesar, vigenere)
+	encoded := new(bytes.Buffer)
+	// when
+	err := pipeline.Encode(strings.NewReader("abc"), encoded)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "bdd", encoded.String())
+}
+
+func Test_NewPipeline_errors(t *testing.T) {
+	// given
+	aesGcm, _ := NewAesGcm("correct horse", 16)
+	railFence, _ := NewRailFence(3, DefaultBlockSize)
+	inputs := [][]*Cipher{
+		{},
+		{NewMirror(), NewByteMirror()},
+		{NewByteMirror(), aesGcm},
+		{NewMirror(), railFence},
+	}
+	expectedErrs := []error{ErrEmptyPipeline, ErrMixedUnits, ErrChainedStreamCipher, ErrChainedBlockCipher}
+	for i, input := range inputs {
+		// when
+		_, err := NewPipeline(input...)
+		// then
+		assert.Equal(t, expectedErrs[i], err)
+	}
+}
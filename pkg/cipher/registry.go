@@ -0,0 +1,212 @@
+package cipher
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/mat-sik/encoder-decoder/internal/algorithms"
+)
+
+// Unit tells whether a cipher operates on UTF-8 encoded runes or on raw bytes.
+type Unit string
+
+const (
+	RuneUnit Unit = "rune"
+	ByteUnit Unit = "byte"
+)
+
+type ParamType string
+
+const (
+	IntType      ParamType = "integer"
+	StringType   ParamType = "string"
+	AlphabetType ParamType = "alphabet"
+)
+
+// Param describes a parameter of an algorithm. Min and Max bound the IntType values, unless both are zero.
+// The Secret parameters are never written to the container Header.
+type Param struct {
+	Name        string
+	Type        ParamType
+	Description string
+	Required    bool
+	Secret      bool
+	Default     string
+	Min         int
+	Max         int
+}
+
+// Params holds the parameter values validated against the Param schema, keyed by the parameter names.
+type Params map[string]any
+
+func (params Params) Has(name string) bool {
+	_, ok := params[name]
+	return ok
+}
+
+func (params Params) Int(name string) int {
+	value, _ := params[name].(int)
+	return value
+}
+
+func (params Params) String(name string) string {
+	value, _ := params[name].(string)
+	return value
+}
+
+func (params Params) Alphabets(name string) []*Alphabet {
+	value, _ := params[name].([]*Alphabet)
+	return value
+}
+
+// Algorithm describes an algorithm to the registry. New is called with the unit being one of the Units
+// and with the params validated against the Params schema.
+type Algorithm struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Units       []Unit
+	Params      []Param
+	New         func(unit Unit, params Params) (*Cipher, error)
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = make(map[string]Algorithm)
+	registryNames []string
+)
+
+// Register makes the algorithm available by its name and aliases, it is meant to be called from init.
+// It panics if any of the names is already taken, just like the registration of the database/sql drivers.
+func Register(algorithm Algorithm) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	names := append([]string{algorithm.Name}, algorithm.Aliases...)
+	for _, name := range names {
+		if _, ok := registry[name]; ok {
+			panic("cipher: algorithm registered twice: " + name)
+		}
+	}
+	for _, name := range names {
+		registry[name] = algorithm
+	}
+	registryNames = append(registryNames, algorithm.Name)
+	slices.Sort(registryNames)
+}
+
+// Lookup finds the algorithm by its name or by one of its aliases.
+func Lookup(name string) (Algorithm, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	algorithm, ok := registry[name]
+	return algorithm, ok
+}
+
+// Algorithms returns the registered algorithms sorted by their names.
+func Algorithms() []Algorithm {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	algorithms := make([]Algorithm, 0, len(registryNames))
+	for _, name := range registryNames {
+		algorithms = append(algorithms, registry[name])
+	}
+	return algorithms
+}
+
+// NewCipher validates the raw parameter values against the Params schema, filling in the defaults, and
+// creates the cipher with them.
+func (algorithm Algorithm) NewCipher(unit Unit, rawParams map[string]string) (*Cipher, error) {
+	if !slices.Contains(algorithm.Units, unit) {
+		return nil, &ErrUnsupportedUnit{algorithm.Name, unit}
+	}
+	for name := range rawParams {
+		if _, ok := algorithm.findParam(name); !ok {
+			return nil, &ErrUnsupportedParam{algorithm.Name, name}
+		}
+	}
+	params := make(Params, len(algorithm.Params))
+	for _, param := range algorithm.Params {
+		rawValue, ok := rawParams[param.Name]
+		switch {
+		case !ok && param.Required:
+			return nil, &ErrMissingParam{algorithm.Name, param.Name}
+		case !ok && param.Default == "":
+			continue
+		case !ok:
+			rawValue = param.Default
+		}
+		value, err := param.parse(rawValue)
+		if err != nil {
+			return nil, &ErrInvalidParam{algorithm.Name, param.Name, rawValue, err.Error()}
+		}
+		params[param.Name] = value
+	}
+	return algorithm.New(unit, params)
+}
+
+func (algorithm Algorithm) findParam(name string) (Param, bool) {
+	for _, param := range algorithm.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return Param{}, false
+}
+
+func (param Param) parse(rawValue string) (any, error) {
+	switch param.Type {
+	case IntType:
+		value, err := strconv.Atoi(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		if (param.Min != 0 || param.Max != 0) && (value < param.Min || value > param.Max) {
+			return nil, fmt.Errorf("expected an integer within %d-%d", param.Min, param.Max)
+		}
+		return value, nil
+	case AlphabetType:
+		return algorithms.GetAlphabets(rawValue)
+	default:
+		return rawValue, nil
+	}
+}
+
+type ErrUnsupportedUnit struct {
+	Alg  string
+	Unit Unit
+}
+
+func (e *ErrUnsupportedUnit) Error() string {
+	return fmt.Sprintf("algorithm: %s does not support unit: %s", e.Alg, e.Unit)
+}
+
+type ErrMissingParam struct {
+	Alg   string
+	Param string
+}
+
+func (e *ErrMissingParam) Error() string {
+	return fmt.Sprintf("algorithm: %s requires parameter: %s", e.Alg, e.Param)
+}
+
+type ErrUnsupportedParam struct {
+	Alg   string
+	Param string
+}
+
+func (e *ErrUnsupportedParam) Error() string {
+	return fmt.Sprintf("algorithm: %s does not take parameter: %s", e.Alg, e.Param)
+}
+
+type ErrInvalidParam struct {
+	Alg    string
+	Param  string
+	Value  string
+	Reason string
+}
+
+func (e *ErrInvalidParam) Error() string {
+	return fmt.Sprintf("invalid value: %s of parameter: %s of algorithm: %s, %s", e.Value, e.Param, e.Alg, e.Reason)
+}
@@ -0,0 +1,70 @@
+package cipher_test
+
+import (
+	"bytes"
+	"github.com/mat-sik/encoder-decoder/pkg/cipher"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// The algorithms of another package are registered in its init, using the exported cipher constructors.
+func init() {
+	cipher.Register(cipher.Algorithm{
+		Name:        "test-swapcase",
+		Description: "swaps the case of the letters, used by the tests only",
+		Units:       []cipher.Unit{cipher.RuneUnit},
+		New: func(_ cipher.Unit, _ cipher.Params) (*cipher.Cipher, error) {
+			return cipher.NewRuneCipher(swapCase, swapCase), nil
+		},
+	})
+	cipher.Register(cipher.Algorithm{
+		Name:        "test-shift-position",
+		Description: "shifts the runes by their position, used by the tests only",
+		Units:       []cipher.Unit{cipher.RuneUnit, cipher.ByteUnit},
+		Params: []cipher.Param{
+			{Name: "step", Type: cipher.IntType, Description: "shift of every byte", Default: "1", Min: 1, Max: 255},
+		},
+		New: func(unit cipher.Unit, params cipher.Params) (*cipher.Cipher, error) {
+			if unit == cipher.ByteUnit {
+				step := byte(params.Int("step"))
+				return cipher.NewByteCipher(func(b byte) byte { return b + step }, func(b byte) byte { return b - step }), nil
+			}
+			return cipher.NewPositionalRuneCipher(
+				func(position int, r rune) rune { return r + rune(position%2) },
+				func(position int, r rune) rune { return r - rune(position%2) },
+			), nil
+		},
+	})
+}
+
+func swapCase(r rune) rune {
+	if unicode.IsUpper(r) {
+		return unicode.ToLower(r)
+	}
+	return unicode.ToUpper(r)
+}
+
+func Test_Register_externalAlgorithm(t *testing.T) {
+	// given
+	names := []string{"test-swapcase", "test-shift-position", "test-shift-position"}
+	units := []cipher.Unit{cipher.RuneUnit, cipher.RuneUnit, cipher.ByteUnit}
+	expectedEncoded := []string{"hELLO, wORLD!", "Hflmo- Xosle!", "Ifmmp-!Xpsme\""}
+	for i, name := range names {
+		algorithm, ok := cipher.Lookup(name)
+		assert.True(t, ok, name)
+		algCipher, err := algorithm.NewCipher(units[i], map[string]string{})
+		assert.NoError(t, err, name)
+		encoded := new(bytes.Buffer)
+		decoded := new(bytes.Buffer)
+		// when
+		encodeErr := algCipher.Encode(strings.NewReader("Hello, World!"), encoded)
+		decodeErr := algCipher.Decode(bytes.NewReader(encoded.Bytes()), decoded)
+		// then
+		assert.NoError(t, encodeErr, name)
+		assert.NoError(t, decodeErr, name)
+		assert.Equal(t, expectedEncoded[i], encoded.String(), name)
+		assert.Equal(t, "Hello, World!", decoded.String(), name)
+	}
+}
@@ -0,0 +1,97 @@
+package cipher
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(Algorithm{
+		Name:        "test-rot",
+		Aliases:     []string{"test-rotate"},
+		Description: "rotates the Latin letters, used by the tests only",
+		Units:       []Unit{RuneUnit},
+		Params: []Param{
+			{Name: "rotation", Type: IntType, Description: "rotation", Default: "13", Min: 1, Max: 25},
+		},
+		New: func(_ Unit, params Params) (*Cipher, error) {
+			return NewAlphabetCaesar(params.Int("rotation"), LatinLowercase, LatinUppercase), nil
+		},
+	})
+}
+
+func Test_Lookup_alias(t *testing.T) {
+	// given
+	names := []string{"test-rot", "test-rotate", "shift", "vigenère"}
+	expectedNames := []string{"test-rot", "test-rot", "caesar", "vigenere"}
+	for i, name := range names {
+		// when
+		algorithm, ok := Lookup(name)
+		// then
+		assert.True(t, ok, name)
+		assert.Equal(t, expectedNames[i], algorithm.Name, name)
+	}
+}
+
+func Test_Algorithms(t *testing.T) {
+	// given
+	expectedNames := []string{"aes-gcm", "affine", "atbash", "caesar", "columnar", "double-transposition", "mirror", "railfence", "substitution", "test-rot", "test-shift-position", "test-swapcase", "vigenere", "xor"}
+	// when
+	algorithms := Algorithms()
+	// then
+	names := make([]string, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		names = append(names, algorithm.Name)
+	}
+	assert.Equal(t, expectedNames, names)
+}
+
+func Test_Register_duplicate(t *testing.T) {
+	// given
+	algorithm := Algorithm{Name: "rot13", Aliases: []string{"shift"}}
+	// when
+	register := func() { Register(algorithm) }
+	// then
+	assert.Panics(t, register)
+	_, ok := Lookup("rot13")
+	assert.False(t, ok)
+}
+
+func Test_Algorithm_NewCipher_default(t *testing.T) {
+	// given
+	algorithm, _ := Lookup("test-rot")
+	encoded := new(bytes.Buffer)
+	// when
+	cipher, err := algorithm.NewCipher(RuneUnit, map[string]string{})
+	assert.NoError(t, err)
+	err = cipher.Encode(strings.NewReader("Hello"), encoded)
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "Uryyb", encoded.String())
+}
+
+func Test_Algorithm_NewCipher_errors(t *testing.T) {
+	// given
+	algorithm, _ := Lookup("test-rot")
+	units := []Unit{ByteUnit, RuneUnit, RuneUnit, RuneUnit}
+	rawParams := []map[string]string{
+		{},
+		{"key": "3"},
+		{"rotation": "26"},
+		{"rotation": "one"},
+	}
+	expectedErrs := []error{
+		&ErrUnsupportedUnit{"test-rot", ByteUnit},
+		&ErrUnsupportedParam{"test-rot", "key"},
+		&ErrInvalidParam{"test-rot", "rotation", "26", "expected an integer within 1-25"},
+		&ErrInvalidParam{"test-rot", "rotation", "one", "expected an integer"},
+	}
+	for i, unit := range units {
+		// when
+		_, err := algorithm.NewCipher(unit, rawParams[i])
+		// then
+		assert.Equal(t, expectedErrs[i], err, rawParams[i])
+	}
+}
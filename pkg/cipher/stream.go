@@ -0,0 +1,109 @@
+package cipher
+
+import (
+	"io"
+
+	"github.com/mat-sik/encoder-decoder/internal/transformer"
+)
+
+// NewEncodingReader returns a reader of the encoded content of the given reader. For the ciphers
+// transforming the stream as a whole or in blocks, the reader is fed by a goroutine, which exits once
+// the reader is read until io.EOF or an error, or once it is closed. Its Close does not close the given
+// reader, so the reader abandoned before io.EOF has to be closed.
+func (cipher *Cipher) NewEncodingReader(reader io.Reader) io.ReadCloser {
+	if cipher.encodeStreamFunc != nil || cipher.encodeBlockFunc != nil {
+		return newStreamReader(reader, cipher.Encode)
+	}
+	return newTransformingReader(reader, cipher.encodeFunc, cipher.encodeByteFunc, cipher.invalidPolicy)
+}
+
+// NewDecodingReader is the decoding counterpart of NewEncodingReader.
+func (cipher *Cipher) NewDecodingReader(reader io.Reader) io.ReadCloser {
+	if cipher.decodeStreamFunc != nil || cipher.decodeBlockFunc != nil {
+		return newStreamReader(reader, cipher.Decode)
+	}
+	return newTransformingReader(reader, cipher.decodeFunc, cipher.decodeByteFunc, cipher.invalidPolicy)
+}
+
+// NewEncodingWriter returns a writer encoding everything written to it into the given writer. Its Close
+// reports the data ending with an incomplete rune, and it does not close the given writer.
+func (cipher *Cipher) NewEncodingWriter(writer io.Writer) io.WriteCloser {
+	if cipher.encodeStreamFunc != nil || cipher.encodeBlockFunc != nil {
+		return newStreamWriter(writer, cipher.Encode)
+	}
+	return newTransformingWriter(writer, cipher.encodeFunc, cipher.encodeByteFunc, cipher.invalidPolicy)
+}
+
+// NewDecodingWriter is the decoding counterpart of NewEncodingWriter.
+func (cipher *Cipher) NewDecodingWriter(writer io.Writer) io.WriteCloser {
+	if cipher.decodeStreamFunc != nil || cipher.decodeBlockFunc != nil {
+		return newStreamWriter(writer, cipher.Decode)
+	}
+	return newTransformingWriter(writer, cipher.decodeFunc, cipher.decodeByteFunc, cipher.invalidPolicy)
+}
+
+func newTransformingReader(
+	reader io.Reader,
+	transformFunc func(position int, r rune) rune,
+	transformByteFunc func(b byte) byte,
+	policy InvalidPolicy,
+) io.ReadCloser {
+	if transformByteFunc != nil {
+		return io.NopCloser(transformer.NewByteReader(reader, transformByteFunc))
+	}
+	return io.NopCloser(transformer.NewReader(reader, transformer.WithPosition(transformFunc), policy))
+}
+
+func newTransformingWriter(
+	writer io.Writer,
+	transformFunc func(position int, r rune) rune,
+	transformByteFunc func(b byte) byte,
+	policy InvalidPolicy,
+) io.WriteCloser {
+	if transformByteFunc != nil {
+		return transformer.NewByteWriter(writer, transformByteFunc)
+	}
+	return transformer.NewWriter(writer, transformer.WithPosition(transformFunc), policy)
+}
+
+// newStreamReader runs the transformation in a goroutine writing to the pipe. Closing the pipe reader
+// fails the pending and the following writes of the transformation, which makes the goroutine exit.
+func newStreamReader(reader io.Reader, transformStreamFunc func(io.Reader, io.Writer) error) io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		_ = pipeWriter.CloseWithError(transformStreamFunc(reader, pipeWriter))
+	}()
+	return pipeReader
+}
+
+// streamWriter runs the transformation in a goroutine reading what is written to the pipe, its Close
+// waits for the transformation to finish and reports its failure.
+type streamWriter struct {
+	pipeWriter *io.PipeWriter
+	done       chan error
+	err        error
+}
+
+func newStreamWriter(writer io.Writer, transformStreamFunc func(io.Reader, io.Writer) error) io.WriteCloser {
+	pipeReader, pipeWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := transformStreamFunc(pipeReader, writer)
+		_ = pipeReader.CloseWithError(err)
+		done <- err
+	}()
+	return &streamWriter{pipeWriter: pipeWriter, done: done}
+}
+
+func (streamWriter *streamWriter) Write(p []byte) (int, error) {
+	return streamWriter.pipeWriter.Write(p)
+}
+
+func (streamWriter *streamWriter) Close() error {
+	if streamWriter.done != nil {
+		_ = streamWriter.pipeWriter.Close()
+		streamWriter.err = <-streamWriter.done
+		streamWriter.done = nil
+	}
+	return streamWriter.err
+}
@@ -0,0 +1,99 @@
+package cipher
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_NewEncodingReader_roundTrip(t *testing.T) {
+	for name, cipher := range newTestCiphers(t) {
+		// given
+		reader := cipher.NewDecodingReader(cipher.NewEncodingReader(strings.NewReader(plaintext)))
+		// when
+		result, err := io.ReadAll(reader)
+		// then
+		assert.NoError(t, err, name)
+		assert.Equal(t, plaintext, string(result), name)
+	}
+}
+
+// endlessReader never reaches io.EOF, so only closing the stream reader stops its goroutine.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+// waitForGoroutines waits up to a second for the number of the goroutines to drop to the given one.
+func waitForGoroutines(goroutines int) int {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	return runtime.NumGoroutine()
+}
+
+func Test_NewEncodingReader_abandoned(t *testing.T) {
+	aesGcm, _ := NewAesGcm("correct horse", 16)
+	railFence, _ := NewRailFence(3, DefaultBlockSize)
+	for name, cipher := range map[string]*Cipher{"aes-gcm": aesGcm, "railfence": railFence} {
+		// given
+		goroutines := runtime.NumGoroutine()
+		reader := cipher.NewEncodingReader(endlessReader{})
+		_, readErr := io.ReadFull(reader, make([]byte, 16))
+		// when
+		closeErr := reader.Close()
+		// then
+		assert.NoError(t, readErr, name)
+		assert.NoError(t, closeErr, name)
+		assert.LessOrEqual(t, waitForGoroutines(goroutines), goroutines, name)
+	}
+}
+
+func Test_NewEncodingWriter_roundTrip(t *testing.T) {
+	for name, cipher := range newTestCiphers(t) {
+		// given
+		decoded := new(bytes.Buffer)
+		decodingWriter := cipher.NewDecodingWriter(decoded)
+		encodingWriter := cipher.NewEncodingWriter(decodingWriter)
+		// when
+		_, writeErr := io.WriteString(encodingWriter, plaintext)
+		encodingCloseErr := encodingWriter.Close()
+		decodingCloseErr := decodingWriter.Close()
+		// then
+		assert.NoError(t, writeErr, name)
+		assert.NoError(t, encodingCloseErr, name)
+		assert.NoError(t, decodingCloseErr, name)
+		assert.Equal(t, plaintext, decoded.String(), name)
+	}
+}
+
+func Test_NewEncodingReader_invalidInput(t *testing.T) {
+	// given
+	cipher := NewAlphabetCaesar(3, LatinLowercase)
+	reader := cipher.NewEncodingReader(bytes.NewReader([]byte{'a', 0xFF}))
+	// when
+	_, err := io.ReadAll(reader)
+	// then
+	assert.Error(t, err)
+}
+
+func Test_NewEncodingWriter_incompleteRune(t *testing.T) {
+	// given
+	cipher := NewAlphabetCaesar(3, LatinLowercase)
+	writer := cipher.NewEncodingWriter(new(bytes.Buffer))
+	// when
+	_, writeErr := writer.Write([]byte("ab\xC5"))
+	closeErr := writer.Close()
+	// then
+	assert.NoError(t, writeErr)
+	assert.Error(t, closeErr)
+}